@@ -1,7 +1,12 @@
 package Engine
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 
 	gd "graphics.gd/internal"
@@ -122,3 +127,127 @@ func RaiseWarning(v ...any) { //gd:push_warning
 	}
 	gdextension.Host.Builtin.Functions.Call(push_warning_fn, nil, gdextension.ShapeVariants(len(variants)), gdextension.CallAccepts[any](&variants[0]))
 }
+
+// LogOptions configures a [LogHandler].
+type LogOptions struct {
+	Level slog.Leveler // minimum level to log, defaults to [slog.LevelInfo].
+	Rich  bool         // colour the output with [PrintRich].
+}
+
+// LogHandler is a [slog.Handler] that routes log records to the engine, such that they show up
+// in the editor. Errors and warnings are pushed to the debugger with the source location of the
+// caller, info is printed to the console and debug is printed in verbose mode (see [Logv]).
+// Attributes are rendered as a key=value suffix.
+//
+//	slog.SetDefault(slog.New(Engine.NewLogHandler(nil)))
+type LogHandler struct {
+	options LogOptions
+	prefix  string // pre-rendered attributes from WithAttrs.
+	group   string // group prefix for attribute keys, from WithGroup.
+}
+
+// NewLogHandler returns a new [LogHandler] with the given options (which may be nil).
+func NewLogHandler(options *LogOptions) *LogHandler {
+	var handler LogHandler
+	if options != nil {
+		handler.options = *options
+	}
+	if handler.options.Level == nil {
+		handler.options.Level = slog.LevelInfo
+	}
+	return &handler
+}
+
+// Enabled implements [slog.Handler].
+func (h *LogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.options.Level.Level()
+}
+
+// Handle implements [slog.Handler].
+func (h *LogHandler) Handle(_ context.Context, record slog.Record) error {
+	var text strings.Builder
+	if h.options.Rich && record.Level < slog.LevelWarn {
+		color := "gray"
+		if record.Level >= slog.LevelInfo {
+			color = "cyan"
+		}
+		fmt.Fprintf(&text, "[color=%s]%s[/color] ", color, record.Level)
+	}
+	text.WriteString(record.Message)
+	text.WriteString(h.prefix)
+	record.Attrs(func(attr slog.Attr) bool {
+		h.appendAttr(&text, h.group, attr)
+		return true
+	})
+	switch {
+	case record.Level >= slog.LevelError:
+		name, file, line := source(record.PC)
+		gdextension.Host.Log.Error(text.String(), "", name, file, line, false)
+	case record.Level >= slog.LevelWarn:
+		name, file, line := source(record.PC)
+		gdextension.Host.Log.Warning(text.String(), "", name, file, line, false)
+	case record.Level >= slog.LevelInfo && h.options.Rich:
+		PrintRich(text.String())
+	case record.Level >= slog.LevelInfo:
+		Println(text.String())
+	default:
+		Logv(text.String())
+	}
+	return nil
+}
+
+// WithAttrs implements [slog.Handler].
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var text strings.Builder
+	text.WriteString(h.prefix)
+	for _, attr := range attrs {
+		h.appendAttr(&text, h.group, attr)
+	}
+	var handler = *h
+	handler.prefix = text.String()
+	return &handler
+}
+
+// WithGroup implements [slog.Handler].
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	var handler = *h
+	handler.group = h.group + name + "."
+	return &handler
+}
+
+func (h *LogHandler) appendAttr(text *strings.Builder, group string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			group += attr.Key + "."
+		}
+		for _, attr := range attr.Value.Group() {
+			h.appendAttr(text, group, attr)
+		}
+		return
+	}
+	value := attr.Value.String()
+	if strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+	if h.options.Rich {
+		fmt.Fprintf(text, " [color=gray]%s%s=[/color]%s", group, attr.Key, value)
+	} else {
+		fmt.Fprintf(text, " %s%s=%s", group, attr.Key, value)
+	}
+}
+
+// source returns the function, file and line of the given program counter.
+func source(pc uintptr) (name, file string, line int32) {
+	if pc == 0 {
+		return "", "", 0
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return frame.Function, frame.File, int32(frame.Line)
+}
@@ -0,0 +1,29 @@
+package Performance
+
+import (
+	"sync/atomic"
+
+	"graphics.gd/variant/Callable"
+)
+
+// Counter is a custom monitor that shows up in the editor's debugger under the Monitors tab.
+type Counter struct {
+	value atomic.Int64
+}
+
+// NewCounter adds a custom monitor with the given id, in the form "category/name". The value of
+// the counter can be updated from any goroutine.
+func NewCounter(id string) *Counter {
+	var counter = new(Counter)
+	AddCustomMonitor(id, Callable.New(counter.Value), nil)
+	return counter
+}
+
+// Add delta to the counter.
+func (counter *Counter) Add(delta int) { counter.value.Add(int64(delta)) }
+
+// Set the value of the counter.
+func (counter *Counter) Set(value int) { counter.value.Store(int64(value)) }
+
+// Value returns the current value of the counter.
+func (counter *Counter) Value() int { return int(counter.value.Load()) }
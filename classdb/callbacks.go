@@ -97,6 +97,7 @@ func init() {
 				gdmemory.Set(gdextension.Pointer(rid), uint64(0))
 			},
 			Notification: func(instance gdextension.ExtensionInstanceID, what int32, reverse bool) {
+				defer gd.Recover()
 				cgoHandle(instance).Value().(*instanceImplementation).Notification(what, reverse)
 			},
 			CheckedCall: func(instance gdextension.ExtensionInstanceID, fn gdextension.FunctionID, result gdextension.Returns[any], args gdextension.Accepts[any]) {
@@ -139,12 +140,14 @@ func init() {
 				}
 			},
 			Free: func(instance gdextension.ExtensionInstanceID) {
+				defer gd.Recover()
+				defer cgoHandle(instance).Delete()
 				cgoHandle(instance).Value().(*instanceImplementation).Free()
-				cgoHandle(instance).Delete()
 			},
 		},
 		Class: gdextension.CallbacksForExtensionClass{
 			Create: func(class gdextension.ExtensionClassID, notify_postinitialize bool) gdextension.Object {
+				defer gd.Recover()
 				return gdextension.Object(pointers.Get(cgoHandle(class).Value().(*classImplementation).CreateInstance(notify_postinitialize)[0])[0])
 			},
 			Method: func(class gdextension.ExtensionClassID, method gdextension.StringName, hash uint32) gdextension.FunctionID {
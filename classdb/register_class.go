@@ -1,6 +1,7 @@
 package classdb
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
@@ -11,7 +12,10 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -40,6 +44,20 @@ import (
 // Tool can be embedded inside a struct to make it run in the editor.
 type Tool interface{ tool() }
 
+// Abstract can be embedded inside a struct to prevent the engine from instantiating it,
+// such that it can only be used as a base class for other classes. It only applies to the
+// struct that embeds it directly, not to the Go subclasses that embed that struct.
+type Abstract interface{ abstract() }
+
+// Runtime can be embedded inside a struct to prevent it from running in the editor, the
+// editor will use a placeholder instead. Unlike classes that do not embed [Tool], it will
+// still be available to create in the editor. Cannot be combined with [Tool].
+type Runtime interface{ runtime() }
+
+// Hidden can be embedded inside a struct to hide it from the editor, such that it will
+// not be available in the create dialog.
+type Hidden interface{ hidden() }
+
 // Deprecated: use a classdb package Extension instead, ie. Node.Extension[MyClass]
 type Extension[T Class, S gd.IsClass] = gdclass.Extension[T, S]
 
@@ -82,6 +100,16 @@ class members within The Engine:
 
   - range can be used to specify the range hint of the member.
   - group can be used to group members together in the editor.
+  - connect can be used on func fields to connect them to a signal when the
+    class is ready, ie. connect:"Path/To/Button.pressed" or connect:"renamed"
+    for the class's own signals. They are disconnected when exiting the tree.
+  - virtual can be used on func fields to declare a virtual method that
+    can be overridden by scripts and subclasses, ie. virtual:"_on_fire(power)"
+    calling the field will dispatch to the override, or else to the func
+    value that the field had when the class was instantiated.
+  - node can be used on Node fields to refer to an existing node by path
+    (relative to the class), the field is resolved before Ready is called.
+    Use node:",unique" or node:"Name,unique" to look up a %UniqueName node.
 
 This function accepts a variable number of additional arguments,
 they may either be func, map[string]any (where each any is a func),
@@ -94,10 +122,16 @@ instantiated from within The Engine.
 
 If the Struct extends [EditorPluginClass] then it will be added
 to the editor as a plugin.
+
+Embed [Tool], [Abstract], [Runtime] or [Hidden] inside the Struct
+to control how the class is treated by the editor.
 */
 func Register[T Class](exports ...any) {
 	var superType = gdclass.SuperType(([1]T{})[0])
 	var super = reflect.New(superType).Elem().Interface()
+	if !slices.Contains(gdclass.Declared, reflect.TypeFor[T]()) {
+		gdclass.Declared = append(gdclass.Declared, reflect.TypeFor[T]())
+	}
 	register := func() {
 		var classType = reflect.TypeFor[T]()
 		var base = classType
@@ -130,9 +164,28 @@ func Register[T Class](exports ...any) {
 		case Tool:
 			tool = true
 		}
+		var abstract bool // only when embedded directly, as Go subclasses embed the abstract class.
+		for i := range classType.NumField() {
+			if field := classType.Field(i); field.Anonymous && field.Type == reflect.TypeFor[Abstract]() {
+				abstract = true
+			}
+		}
+		_, runtimeOnly := any(([1]T{})[0]).(Runtime)
+		_, hidden := any(([1]T{})[0]).(Hidden)
+		if tool && runtimeOnly {
+			panic("gdextension.RegisterClass: Class type cannot be both a Tool and Runtime class")
+		}
 		var reference T
 		var className = pointers.Pin(gd.NewStringName(rename))
 		var superName = pointers.Pin(gd.NewStringName(nameOf(superType)))
+		var baseName = superName
+		var parentType = superType
+		if parentType.Kind() == reflect.Pointer {
+			parentType = parentType.Elem()
+		}
+		if parent, ok := gdclass.Registered.Load(parentType); ok && parent.(*classImplementation).Abstract {
+			baseName = parent.(*classImplementation).Base // abstract classes cannot be constructed, use the nearest concrete class.
+		}
 
 		var refCounted bool
 		switch super.(type) {
@@ -144,23 +197,36 @@ func Register[T Class](exports ...any) {
 			Name:           className,
 			Super:          superName,
 			Type:           classType,
+			Base:           baseName,
 			Tool:           tool,
+			Abstract:       abstract,
+			Runtime:        runtimeOnly,
+			Exposed:        !hidden,
 			RefCounted:     refCounted,
 			VirtualMethods: reference.Virtual,
 			Constructor: func() reflect.Value {
 				return reflect.New(classType)
 			},
 		}
+		if existing, ok := registeredByName(rename); ok && existing.Type != classType {
+			if err := existing.unregister(); err != nil {
+				EngineClass.Raise(fmt.Errorf("gdextension.RegisterClass: cannot re-register %s: %w", rename, err))
+				className.Free()
+				superName.Free()
+				return
+			}
+		}
+		var migrations []liveInstance
+		if placeholder, ok := placeholders.Load(rename); ok {
+			migrations = placeholder.(*classImplementation).live()
+			placeholder.(*classImplementation).remove() // the live instances are migrated below.
+		}
 		gdclass.Registered.Store(classType, impl)
 
-		gdextension.Host.ClassDB.Register.Class(pointers.Get(className), pointers.Get(superName), gdextension.ExtensionClassID(cgoNewHandle(impl)), false, false, true, false, gdextension.String{})
+		impl.Handle = cgoNewHandle(impl)
+		gdextension.Host.ClassDB.Register.Class(pointers.Get(className), pointers.Get(superName), gdextension.ExtensionClassID(impl.Handle), false, abstract, !hidden, runtimeOnly, gdextension.String{})
 
-		gd.RegisterCleanup(func() {
-			gdextension.Host.ClassDB.Register.Removal(pointers.Get(className))
-			gdclass.Registered.Delete(classType)
-			className.Free()
-			superName.Free()
-		})
+		gd.RegisterCleanup(impl.remove)
 		var (
 			documentation = make(map[string]string)
 		)
@@ -225,8 +291,12 @@ func Register[T Class](exports ...any) {
 		default:
 			registerClassInformation(className, rename, nameOf(superType), classType, documentation, method_renames)
 			registerSignals(className, classType)
+			if err := registerVirtuals(className, classType); err != nil {
+				EngineClass.Raise(err)
+			}
 			registerMethods(className, classType, method_renames)
 		}
+		impl.migrate(migrations)
 		if registrator, ok := any(reference).(interface{ OnRegister() }); ok {
 			registrator.OnRegister()
 		}
@@ -234,6 +304,7 @@ func Register[T Class](exports ...any) {
 			switch super.(type) {
 			case EditorPluginClass.Any:
 				gdextension.Host.Editor.AddPlugin(pointers.Get(className))
+				impl.Plugin = true
 			}
 		}
 	}
@@ -344,6 +415,12 @@ func registerClassInformation(className gd.StringName, classNameString string, i
 		if _, ok := field.Type.MethodByName("AsNode"); ok || field.Type.Kind() == reflect.Chan {
 			return
 		}
+		if _, ok := field.Tag.Lookup("connect"); ok {
+			return
+		}
+		if _, _, ok := virtualName(field); ok {
+			return
+		}
 		name := String.ToSnakeCase(field.Name)
 		if tag := field.Tag.Get("gd"); tag != "" {
 			if tag == "-" {
@@ -442,9 +519,22 @@ func registerClassInformation(className gd.StringName, classNameString string, i
 type classImplementation struct {
 	Name  gd.StringName
 	Super gd.StringName
+	Base  gd.StringName // nearest non-abstract ancestor, used to construct instances.
 
 	Tool       bool
+	Abstract   bool
+	Runtime    bool
+	Exposed    bool
 	RefCounted bool
+	Plugin     bool
+
+	// Placeholder classes are registered in place of a class that was unregistered while some of
+	// its instances were still alive, these instances become inert until the class is registered
+	// again, at which point they are migrated to it.
+	Placeholder bool
+	Instances   *atomic.Int32 // live instances of a placeholder class, it is removed along with the last one.
+
+	Handle cgoHandle
 
 	Type reflect.Type
 
@@ -457,11 +547,15 @@ func (class classImplementation) IsVirtual() bool {
 }
 
 func (class classImplementation) IsAbstract() bool {
-	return class.Type.Kind() == reflect.Interface
+	return class.Abstract || class.Type.Kind() == reflect.Interface
 }
 
 func (class classImplementation) IsExposed() bool {
-	return true // TODO return false if the Go type is not exported.
+	return class.Exposed
+}
+
+func (class classImplementation) IsRuntime() bool {
+	return class.Runtime
 }
 
 func (class classImplementation) CreateInstance(notify_postinitialize bool) [1]gd.Object {
@@ -469,7 +563,7 @@ func (class classImplementation) CreateInstance(notify_postinitialize bool) [1]g
 }
 
 func (class classImplementation) CreateInstanceFrom(value reflect.Value, notify_postinitialize bool) [1]gd.Object {
-	var super = [1]gd.Object{pointers.New[gd.Object]([3]uint64{uint64(gdextension.Host.Objects.Make(pointers.Get(class.Super)))})}
+	var super = [1]gd.Object{pointers.New[gd.Object]([3]uint64{uint64(gdextension.Host.Objects.Make(pointers.Get(class.Base)))})}
 	if class.RefCounted {
 		gd.RefCounted(super[0]).InitRef()
 	}
@@ -484,7 +578,7 @@ func (class classImplementation) CreateInstanceFrom(value reflect.Value, notify_
 }
 
 func (class classImplementation) CreateGoInstanceFrom(value reflect.Value, notify_postinitialize bool) [1]gd.Object {
-	var super = [1]gd.Object{pointers.New[gd.Object]([3]uint64{uint64(gdextension.Host.Objects.Make(pointers.Get(class.Super)))})}
+	var super = [1]gd.Object{pointers.New[gd.Object]([3]uint64{uint64(gdextension.Host.Objects.Make(pointers.Get(class.Base)))})}
 	if class.RefCounted {
 		gd.RefCounted(super[0]).InitRef()
 	}
@@ -518,6 +612,10 @@ func (class classImplementation) reloadInstance(value reflect.Value, super [1]gd
 			name = tag
 		}
 		name, _, _ = strings.Cut(name, "(")
+		if virtual, _, ok := virtualName(field); ok {
+			injectVirtual(extensionClass, virtual, rvalue.Elem())
+			continue
+		}
 		// Signal fields need to have their values injected into the field, so that they can be used (emitted).
 		if reflect.PointerTo(field.Type).Implements(reflect.TypeFor[Signal.Pointer]()) {
 			signal := pointers.Pin(gd.NewSignalOf(super, gd.NewStringName(name)))
@@ -543,16 +641,23 @@ func (class classImplementation) reloadInstance(value reflect.Value, super [1]gd
 	if len(signals) > 0 {
 		go manageSignals(Object.Instance(super[0].AsObject()).ID(), chSignals)
 	}
-	return &instanceImplementation{
+	instance := &instanceImplementation{
 		object:   pointers.Get(super[0])[0],
 		Value:    value.Addr().Interface().(gdclass.Pointer),
 		signals:  signals,
-		isEditor: !class.Tool && EngineClass.IsEditorHint(),
+		isEditor: (!class.Tool && EngineClass.IsEditorHint()) || class.Placeholder,
+	}
+	if class.Placeholder {
+		if placeholder, ok := placeholders.Load(nameOf(class.Type)); ok {
+			instance.placeholder = placeholder.(*classImplementation)
+			instance.placeholder.Instances.Add(1)
+		}
 	}
+	return instance
 }
 
 func (class classImplementation) GetVirtual(name gd.StringName) any {
-	if !class.Tool && EngineClass.IsEditorHint() {
+	if class.Placeholder || (!class.Tool && EngineClass.IsEditorHint()) {
 		return nil
 	}
 	var virtual = class.VirtualMethods(name.String())
@@ -595,17 +700,29 @@ func (class classImplementation) GetVirtual(name gd.StringName) any {
 }
 
 type instanceImplementation struct {
-	object  uint64
-	Value   gdclass.Pointer
-	signals []signalChan
+	object      uint64
+	Value       gdclass.Pointer
+	signals     []signalChan
+	connections []connection
+
+	mutex  sync.Mutex // protects ctx and cancel.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	placeholder *classImplementation // placeholder class that the instance was handed over to, if any.
+
+	freed atomic.Bool // read by [Context] from any goroutine.
 
 	// FIXME use a bitfield for these booleans.
-	isEditor, freed bool
+	isEditor, readied bool // isEditor is also set for the instances of a placeholder class.
 }
 
 var lastGC int
 
+// OnCreate calls the creation hooks of the instance, panics are recovered according to the panic
+// policy, so that the instance is still created.
 func (instance *instanceImplementation) OnCreate(value reflect.Value) {
+	defer gd.Recover()
 	if impl, ok := instance.Value.(interface {
 		OnCreate()
 	}); ok {
@@ -624,7 +741,15 @@ func (instance *instanceImplementation) OnCreate(value reflect.Value) {
 }
 
 func (instance *instanceImplementation) Notification(what int32, reversed bool) {
-	if what == 13 { // NOTIFICATION_READY
+	switch what {
+	case 10: // NOTIFICATION_ENTER_TREE
+		if instance.readied && !instance.isEditor {
+			instance.connectSignals()
+		}
+	case 11: // NOTIFICATION_EXIT_TREE
+		instance.disconnectSignals()
+		instance.cancelContext()
+	case 13: // NOTIFICATION_READY
 		instance.ready()
 	}
 	if !instance.isEditor {
@@ -663,16 +788,28 @@ func (instance *instanceImplementation) GetRID() gd.RID {
 	return 0
 }
 
-func (instance *instanceImplementation) Free() {
-	if instance.freed {
-		return
-	}
+// closeSignals closes the channels of the instance's signal fields and releases its signals.
+func (instance *instanceImplementation) closeSignals() {
 	for _, signal := range instance.signals {
 		if signal.rvalue.IsValid() {
 			signal.rvalue.Close()
 		}
 		signal.signal.Free()
 	}
+	instance.signals = nil
+}
+
+func (instance *instanceImplementation) Free() {
+	if instance.freed.Swap(true) {
+		return
+	}
+	instance.cancelContext()
+	instance.closeSignals()
+	for _, conn := range instance.connections {
+		conn.signal.Free()
+		conn.callable.Free()
+	}
+	instance.connections = nil
 	rvalue := reflect.ValueOf(instance.Value).Elem()
 	for _, field := range reflect.VisibleFields(rvalue.Type()) {
 		if !field.IsExported() || field.Name == "Extension" || rvalue.FieldByIndex(field.Index).IsZero() {
@@ -696,11 +833,13 @@ func (instance *instanceImplementation) Free() {
 			}
 		}
 	}
-	switch onfree := instance.Value.(type) {
-	case interface{ OnFree() }:
-		onfree.OnFree()
+	instance.release()
+	if onfree, ok := instance.Value.(interface{ OnFree() }); ok {
+		func() {
+			defer gd.Recover()
+			onfree.OnFree()
+		}()
 	}
-	instance.freed = true
 }
 
 // ready is responsible for asserting the scene tree for struct members that implement
@@ -720,11 +859,13 @@ func (instance *instanceImplementation) ready() {
 		}
 		instance.assertChild(rvalue.FieldByIndex(field.Index).Addr().Interface(), field, parent, parent)
 	}
+	instance.readied = true
 	if !instance.isEditor {
 		switch ready := instance.Value.(type) {
 		case interface{ Ready() }:
 			ready.Ready()
 		}
+		instance.connectSignals() // after Ready, so that the handlers can be assigned there.
 	}
 }
 
@@ -735,6 +876,12 @@ func (instance *instanceImplementation) assertChild(value any, field reflect.Str
 	var (
 		rvalue = reflect.ValueOf(value)
 	)
+	if _, ok := field.Tag.Lookup("node"); ok {
+		if !instance.isEditor {
+			instance.resolveNode(rvalue, field, parent)
+		}
+		return
+	}
 	nodeType := reflect.TypeOf([0]isNode{}).Elem()
 	if !field.Type.Implements(nodeType) && !reflect.PointerTo(field.Type).Implements(nodeType) {
 		if field.Type.Kind() == reflect.Struct {
@@ -809,3 +956,39 @@ func (instance *instanceImplementation) assertChild(value any, field reflect.Str
 		pointers.End(node[0])
 	}
 }
+
+// resolveNode looks up the node referred to by the 'node' tag of the given field (relative to
+// the parent) and assigns it to the field. Unlike regular Node fields, the node is expected to
+// already exist and it will not be created if it is missing.
+func (instance *instanceImplementation) resolveNode(rvalue reflect.Value, field reflect.StructField, parent [1]gdclass.Node) {
+	className := reflect.TypeOf(instance.Value).Elem().Name()
+	name, option, _ := strings.Cut(field.Tag.Get("node"), ",")
+	if name == "" {
+		name = field.Name
+	}
+	if option == "unique" && !strings.HasPrefix(name, "%") {
+		name = "%" + name
+	}
+	path := Path.ToNode(String.New(name))
+	if !NodeClass.Advanced(parent).HasNode(path) {
+		EngineClass.Raise(fmt.Errorf("gd.Register: Node %s.%s not found at path %q", className, field.Name, name))
+		return
+	}
+	var node = NodeClass.Advanced(parent).GetNode(path)
+	defer pointers.End(node[0])
+	castable, ok := rvalue.Interface().(gd.IsClassCastable)
+	native := gd.ExtensionInstanceLookup(gdextension.Object(pointers.Get(node[0])[0]))
+	if native != nil && (reflect.TypeOf(native) == field.Type || !ok) {
+		if reflect.TypeOf(native) != field.Type {
+			EngineClass.Raise(fmt.Errorf("gd.Register: Node %s.%s at path %q is a %s, not a %s", className, field.Name, name, reflect.TypeOf(native).Elem().Name(), field.Type))
+			return
+		}
+		rvalue.Elem().Set(reflect.ValueOf(native))
+		return
+	}
+	// native class fields accept any node that can be cast to them, including Go subclasses.
+	if !ok || !castable.SetObject([1]gd.Object{pointers.Raw[gd.Object](pointers.Get(node[0]))}) {
+		EngineClass.Raise(fmt.Errorf("gd.Register: Node %s.%s at path %q is a %s, not a %s", className, field.Name, name, pointers.Raw[gd.Object](pointers.Get(node[0])).GetClass().String(), field.Type))
+		return
+	}
+}
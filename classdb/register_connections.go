@@ -0,0 +1,99 @@
+package classdb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	EngineClass "graphics.gd/classdb/Engine"
+	NodeClass "graphics.gd/classdb/Node"
+	gd "graphics.gd/internal"
+	"graphics.gd/internal/gdclass"
+	"graphics.gd/internal/pointers"
+	"graphics.gd/variant/Error"
+	"graphics.gd/variant/Object"
+	"graphics.gd/variant/Path"
+	"graphics.gd/variant/String"
+)
+
+// connection is a signal connection made on behalf of a 'connect' tagged field, it is kept
+// around so that it can be disconnected when the node exits the scene tree.
+type connection struct {
+	object   Object.ID
+	signal   gd.StringName
+	callable gd.Callable
+}
+
+// connectSignals connects each func field with a 'connect' tag to the signal that the tag refers
+// to. The tag is in the form "Path/To/Node.signal_name" where the path is relative to the class, or
+// just "signal_name" to connect to one of the class's own signals. Any previous connections are
+// disconnected first, so that a node that is readied again is not connected twice.
+func (instance *instanceImplementation) connectSignals() {
+	instance.disconnectSignals()
+	self, ok := Object.As[NodeClass.Instance](Object.Instance(gdclass.GetObject(instance.Value)))
+	if !ok {
+		return
+	}
+	rvalue := reflect.ValueOf(instance.Value).Elem()
+	for _, field := range reflect.VisibleFields(rvalue.Type()) {
+		tag, ok := field.Tag.Lookup("connect")
+		if !ok || !field.IsExported() {
+			continue
+		}
+		instance.connectSignal(self, field, tag, rvalue.FieldByIndex(field.Index))
+	}
+}
+
+// connectSignal connects the func field fn to the signal that its 'connect' tag refers to.
+func (instance *instanceImplementation) connectSignal(self NodeClass.Instance, field reflect.StructField, tag string, fn reflect.Value) {
+	className := reflect.TypeOf(instance.Value).Elem().Name()
+	if fn.Kind() != reflect.Func {
+		EngineClass.Raise(fmt.Errorf("gd.Register: %s.%s must be a func in order to connect to %q", className, field.Name, tag))
+		return
+	}
+	if fn.IsNil() {
+		return
+	}
+	path, signal := "", tag
+	if i := strings.LastIndexByte(tag, '.'); i >= 0 {
+		path, signal = tag[:i], tag[i+1:]
+	}
+	var target = self.AsObject()
+	if path != "" {
+		npath := Path.ToNode(String.New(path))
+		if !NodeClass.Advanced(self).HasNode(npath) {
+			EngineClass.Raise(fmt.Errorf("gd.Register: %s.%s cannot connect to %q, node not found at path %q", className, field.Name, tag, path))
+			return
+		}
+		node := NodeClass.Advanced(self).GetNode(npath)
+		target = NodeClass.Instance(node).AsObject()
+		defer pointers.End(node[0])
+	}
+	var conn = connection{
+		object:   Object.Instance(target).ID(),
+		signal:   pointers.Pin(gd.NewStringName(signal)),
+		callable: pointers.Pin(gd.NewCallable(fn.Interface())),
+	}
+	if err := target[0].Connect(conn.signal, conn.callable, 0); err != 0 {
+		EngineClass.Raise(fmt.Errorf("gd.Register: %s.%s cannot connect to %q: %w", className, field.Name, tag, Error.Code(err)))
+		conn.signal.Free()
+		conn.callable.Free()
+		return
+	}
+	instance.connections = append(instance.connections, conn)
+}
+
+// disconnectSignals disconnects all of the connections made by [instanceImplementation.connectSignals].
+func (instance *instanceImplementation) disconnectSignals() {
+	for _, conn := range instance.connections {
+		if target := conn.object.Instance(); target != Object.Nil {
+			if target[0].IsConnected(conn.signal, conn.callable) {
+				target[0].Disconnect(conn.signal, conn.callable)
+			}
+			pointers.End(target[0])
+		}
+		conn.signal.Free()
+		conn.callable.Free()
+	}
+	instance.connections = nil
+}
@@ -0,0 +1,67 @@
+package classdb
+
+import (
+	"context"
+
+	"graphics.gd/internal/gdclass"
+	"graphics.gd/internal/gdextension"
+	"graphics.gd/internal/pointers"
+)
+
+/*
+Context returns a context that is cancelled when the given instance of a registered class exits
+the scene tree, or is freed. Use it to stop any goroutines started on behalf of the instance, so
+that they do not outlive it. If the instance re-enters the scene tree, a fresh context is returned
+by subsequent calls.
+
+	func (node *MyNode) Ready() {
+		go node.fetch(classdb.Context(node))
+	}
+
+If the class is not registered, or the instance has already been freed, then the returned context
+is already cancelled.
+*/
+func Context(class Class) context.Context {
+	obj := gdclass.GetObject(class)
+	if obj == ([1]gdclass.Object{}) {
+		return cancelled()
+	}
+	raw := pointers.Get(obj[0])
+	if raw == [3]uint64{} {
+		return cancelled()
+	}
+	val, ok := handles.Load(uintptr(gdextension.Host.Objects.Extension.Fetch(gdextension.Object(raw[0]))))
+	if !ok {
+		return cancelled()
+	}
+	return val.(*instanceImplementation).context()
+}
+
+func cancelled() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+// context returns the current context for the instance, creating it if needed.
+func (instance *instanceImplementation) context() context.Context {
+	instance.mutex.Lock()
+	defer instance.mutex.Unlock()
+	if instance.freed.Load() {
+		return cancelled()
+	}
+	if instance.ctx == nil {
+		instance.ctx, instance.cancel = context.WithCancel(context.Background())
+	}
+	return instance.ctx
+}
+
+// cancelContext cancels the current context for the instance (if any).
+func (instance *instanceImplementation) cancelContext() {
+	instance.mutex.Lock()
+	defer instance.mutex.Unlock()
+	if instance.cancel != nil {
+		instance.cancel()
+	}
+	instance.ctx, instance.cancel = nil, nil
+}
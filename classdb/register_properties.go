@@ -218,10 +218,15 @@ func (instance *instanceImplementation) Set(name gd.StringName, value gd.Variant
 }
 
 func (instance *instanceImplementation) Get(name gd.StringName) (gd.Variant, bool) {
-	if impl, ok := instance.Value.(interface {
-		Get(string) any
-	}); ok {
+	switch impl := instance.Value.(type) {
+	case interface{ Get(string) any }:
 		return gd.NewVariant(impl.Get(name.String())), true
+	case interface{ Get(string) (any, bool) }:
+		value, ok := impl.Get(name.String())
+		if !ok {
+			return gd.Variant{}, false // leave it to the engine.
+		}
+		return gd.NewVariant(value), true
 	}
 	sname := name.String()
 	rvalue := reflect.ValueOf(instance.Value).Elem()
@@ -0,0 +1,219 @@
+package classdb
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	EngineClass "graphics.gd/classdb/Engine"
+	gd "graphics.gd/internal"
+	"graphics.gd/internal/gdclass"
+	"graphics.gd/internal/gdextension"
+	"graphics.gd/internal/pointers"
+)
+
+/*
+Unregister removes the class registered for T from the engine, along with all of its
+methods, properties and signals. If the class is an editor plugin, then it is removed
+from the editor.
+
+Any instances of the class that are still alive are kept valid by a placeholder class
+of the same name, just like the placeholders the editor uses for classes that cannot
+run in the editor. Placeholder instances keep their property values but no longer
+receive notifications, virtual calls or signals, they are freed as usual and the
+placeholder class is removed along with its last instance.
+
+When a class with the same name is registered again with [Register], the placeholder
+instances are migrated to it: each exported field of the new type is copied from the
+field of the same name and type of the old value (except for func and chan fields) and
+if the new type has an OnMigrate(old any) method, it is called with the old value, so
+that any other state can be carried over. This is also how a changed type replaces a
+class of the same name, as [Register] unregisters any other Go type that was previously
+registered under that name. Go pointers to the old value remain valid, but they are no
+longer updated by the engine.
+
+A class cannot be unregistered while another registered class extends it, as the Go
+type of the subclass embeds the Go type of the class.
+*/
+func Unregister[T Class]() error {
+	impl, ok := gdclass.Registered.Load(reflect.TypeFor[T]())
+	if !ok {
+		return fmt.Errorf("classdb.Unregister: %s is not registered", NameFor[T]())
+	}
+	if err := impl.(*classImplementation).unregister(); err != nil {
+		return fmt.Errorf("classdb.Unregister: %w", err)
+	}
+	return nil
+}
+
+// placeholders registered in place of unregistered classes, by class name.
+var placeholders sync.Map // map[string]*classImplementation
+
+// registeredByName returns the class registered under the given engine class name.
+func registeredByName(name string) (*classImplementation, bool) {
+	var found *classImplementation
+	gdclass.Registered.Range(func(_, value any) bool {
+		if impl, ok := value.(*classImplementation); ok && nameOf(impl.Type) == name {
+			found = impl
+			return false
+		}
+		return true
+	})
+	return found, found != nil
+}
+
+// liveInstance of a class, along with the handle that the engine refers to it by.
+type liveInstance struct {
+	handle   cgoHandle
+	instance *instanceImplementation
+}
+
+// live returns the instances of the class that are still alive.
+func (class *classImplementation) live() []liveInstance {
+	var list []liveInstance
+	handles.Range(func(key, value any) bool {
+		if instance, ok := value.(*instanceImplementation); ok && !instance.freed.Load() && reflect.TypeOf(instance.Value).Elem() == class.Type {
+			list = append(list, liveInstance{handle: cgoHandle(key.(uintptr)), instance: instance})
+		}
+		return true
+	})
+	return list
+}
+
+// unregister removes the class, unless it is extended by another class. Any live instances are
+// handed over to a placeholder class.
+func (class *classImplementation) unregister() error {
+	name := nameOf(class.Type)
+	var child string
+	gdclass.Registered.Range(func(_, value any) bool {
+		if impl, ok := value.(*classImplementation); ok && impl != class && impl.Super.String() == class.Name.String() {
+			child = nameOf(impl.Type)
+			return false
+		}
+		return true
+	})
+	if child != "" {
+		return fmt.Errorf("%s is extended by %s, which must be unregistered first", name, child)
+	}
+	live := class.live()
+	var placeholder = &classImplementation{
+		Name:           pointers.Pin(gd.NewStringName(class.Name.String())),
+		Super:          pointers.Pin(gd.NewStringName(class.Super.String())),
+		Base:           class.Base,
+		Type:           class.Type,
+		Abstract:       class.Abstract,
+		Runtime:        class.Runtime,
+		Exposed:        class.Exposed,
+		RefCounted:     class.RefCounted,
+		Placeholder:    true,
+		VirtualMethods: class.VirtualMethods,
+		Constructor:    class.Constructor,
+		Instances:      new(atomic.Int32),
+	}
+	if class.Base == class.Super {
+		placeholder.Base = placeholder.Super
+	}
+	class.remove()
+	if len(live) == 0 {
+		placeholder.Name.Free()
+		placeholder.Super.Free()
+		return nil
+	}
+	placeholder.Instances.Store(int32(len(live)))
+	placeholder.Handle = cgoNewHandle(placeholder)
+	placeholders.Store(name, placeholder)
+	gdextension.Host.ClassDB.Register.Class(pointers.Get(placeholder.Name), pointers.Get(placeholder.Super), gdextension.ExtensionClassID(placeholder.Handle),
+		false, placeholder.Abstract, placeholder.Exposed, placeholder.Runtime, gdextension.String{})
+	gd.RegisterCleanup(placeholder.remove)
+	for _, live := range live {
+		live.instance.disconnectSignals()
+		live.instance.cancelContext()
+		live.instance.isEditor = true
+		live.instance.placeholder = placeholder
+		gdextension.Host.Objects.Extension.Setup(gdextension.Object(live.instance.object), pointers.Get(placeholder.Name), gdextension.ExtensionInstanceID(live.handle))
+	}
+	return nil
+}
+
+// migrate the instances of a placeholder class to this class, which has just been registered under
+// the same name.
+func (class *classImplementation) migrate(instances []liveInstance) {
+	for _, live := range instances {
+		old := live.instance
+		var migrated *instanceImplementation
+		old.placeholder = nil
+		if reflect.TypeOf(old.Value).Elem() == class.Type {
+			migrated = old
+			migrated.isEditor = !class.Tool && EngineClass.IsEditorHint()
+		} else {
+			value := reflect.New(class.Type)
+			copyFields(value.Elem(), reflect.ValueOf(old.Value).Elem())
+			migrated = class.reloadInstance(value, gdclass.GetObject(old.Value))
+			migrated.readied = old.readied
+			old.closeSignals()
+			old.freed.Store(true)
+			handles.Store(uintptr(live.handle), migrated)
+		}
+		gdextension.Host.Objects.Extension.Setup(gdextension.Object(migrated.object), pointers.Get(class.Name), gdextension.ExtensionInstanceID(live.handle))
+		if hook, ok := migrated.Value.(interface{ OnMigrate(old any) }); ok && !migrated.isEditor {
+			hook.OnMigrate(old.Value)
+		}
+		if migrated.readied && !migrated.isEditor {
+			migrated.connectSignals()
+		}
+	}
+}
+
+// copyFields copies the exported fields of src into the fields of dst with the same name and type,
+// func and chan fields are skipped, as they are bound to the old value.
+func copyFields(dst, src reflect.Value) {
+	for _, field := range reflect.VisibleFields(dst.Type()) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		if kind := field.Type.Kind(); kind == reflect.Func || kind == reflect.Chan {
+			continue
+		}
+		from, ok := src.Type().FieldByName(field.Name)
+		if !ok || !from.IsExported() || from.Type != field.Type {
+			continue
+		}
+		dst.FieldByIndex(field.Index).Set(src.FieldByIndex(from.Index))
+	}
+}
+
+// release the instance from the placeholder class it was handed over to, the placeholder is removed
+// along with its last instance. The removal is deferred, as the engine is still freeing the instance.
+func (instance *instanceImplementation) release() {
+	placeholder := instance.placeholder
+	if placeholder == nil {
+		return
+	}
+	instance.placeholder = nil
+	if placeholder.Instances.Add(-1) == 0 {
+		gd.NewCallable(placeholder.remove).CallDeferred()
+	}
+}
+
+// remove the class from the engine, it is safe to call this more than once.
+func (class *classImplementation) remove() {
+	if class.Placeholder {
+		if current, ok := placeholders.Load(nameOf(class.Type)); !ok || current != class {
+			return
+		}
+		placeholders.Delete(nameOf(class.Type))
+	} else {
+		if current, ok := gdclass.Registered.Load(class.Type); !ok || current != class {
+			return
+		}
+		gdclass.Registered.Delete(class.Type)
+	}
+	if class.Plugin {
+		gdextension.Host.Editor.EndPlugin(pointers.Get(class.Name))
+	}
+	gdextension.Host.ClassDB.Register.Removal(pointers.Get(class.Name))
+	class.Handle.Delete()
+	class.Name.Free()
+	class.Super.Free()
+}
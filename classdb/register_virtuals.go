@@ -0,0 +1,161 @@
+package classdb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	EngineClass "graphics.gd/classdb/Engine"
+	gd "graphics.gd/internal"
+	"graphics.gd/internal/gdclass"
+	"graphics.gd/internal/gdextension"
+	"graphics.gd/internal/pointers"
+	"graphics.gd/variant/String"
+)
+
+// virtualName returns the name of the virtual method declared by a func field with a 'virtual'
+// tag, along with the names of its arguments, ie. `virtual:"_on_fire(power)"`. If the tag is
+// empty then the snake_case name of the field is used.
+func virtualName(field reflect.StructField) (name string, args []string, ok bool) {
+	tag, ok := field.Tag.Lookup("virtual")
+	if !ok || field.Type.Kind() != reflect.Func {
+		return "", nil, false
+	}
+	tag = strings.TrimSuffix(tag, ")")
+	name, list, _ := strings.Cut(tag, "(")
+	if name == "" {
+		name = String.ToSnakeCase(field.Name)
+	}
+	if list != "" {
+		args = strings.Split(list, ",")
+	}
+	return name, args, true
+}
+
+// virtualNames are the interned StringNames of the virtual methods declared by 'virtual' fields,
+// so that they are not allocated again on each call.
+var virtualNames sync.Map // map[string]gd.StringName
+
+// internVirtual returns the interned StringName for the virtual method name.
+func internVirtual(name string) gd.StringName {
+	if method, ok := virtualNames.Load(name); ok {
+		return method.(gd.StringName)
+	}
+	method, _ := virtualNames.LoadOrStore(name, pointers.Pin(gd.NewStringName(name)))
+	return method.(gd.StringName)
+}
+
+// registerVirtuals registers func fields tagged with 'virtual' as virtual methods of the class,
+// such that they can be overridden by scripts and by other extensions that extend the class. An
+// error is returned for any Go method that is meant to override one of them, but does not match
+// its signature.
+func registerVirtuals(class gd.StringName, rtype reflect.Type) error {
+	var errs []error
+	for _, field := range reflect.VisibleFields(rtype) {
+		if !field.IsExported() {
+			continue
+		}
+		name, argNames, ok := virtualName(field)
+		if !ok {
+			continue
+		}
+		ftype := field.Type
+		if method, ok := reflect.PointerTo(rtype).MethodByName(convertName(name)); ok && method.Type.NumIn() > 0 {
+			if bound := reflect.New(rtype).Method(method.Index).Type(); bound != ftype {
+				errs = append(errs, fmt.Errorf("classdb.Register: method %s.%s does not override %s, is %s want %s",
+					rtype.Name(), method.Name, name, bound, ftype))
+			}
+		}
+		var arguments = gdextension.Host.ClassDB.PropertyList.Make(ftype.NumIn())
+		for i := range ftype.NumIn() {
+			argName := fmt.Sprintf("arg%d", i+1)
+			if i < len(argNames) {
+				argName = strings.TrimSpace(argNames[i])
+			}
+			if !propertyOf(class, reflect.StructField{Name: argName, Type: ftype.In(i), Tag: reflect.StructTag(`gd:"` + argName + `"`)}, arguments) {
+				panic(fmt.Sprintf("gdextension: virtual method %s has an argument of unsupported type %v", name, ftype.In(i)))
+			}
+		}
+		var returns = gdextension.Host.ClassDB.PropertyList.Make(ftype.NumOut())
+		if ftype.NumOut() > 0 {
+			if !propertyOf(class, reflect.StructField{Name: "result", Type: ftype.Out(0)}, returns) {
+				panic(fmt.Sprintf("gdextension: virtual method %s has a return value of unsupported type %v", name, ftype.Out(0)))
+			}
+		}
+		gdextension.Host.ClassDB.Register.Virtual(pointers.Get(class), pointers.Get(internVirtual(name)),
+			gdextension.MethodFlagNormal|gdextension.MethodFlagVirtual, returns, arguments)
+		gdextension.Host.ClassDB.PropertyList.Free(arguments)
+		gdextension.Host.ClassDB.PropertyList.Free(returns)
+	}
+	return errors.Join(errs...)
+}
+
+// injectVirtual replaces the func value of a 'virtual' field with one that dispatches to the override
+// of the virtual method (if any) by a script or subclass. Go subclasses override the virtual method
+// with a method named after it, ie. OnFire for _on_fire, just like they would for the virtual methods
+// of the engine. Otherwise the original func value is called as the default implementation, if it is
+// nil, then the zero values are returned.
+func injectVirtual(class gdclass.Pointer, name string, rvalue reflect.Value) {
+	var (
+		ftype    = rvalue.Type()
+		fallback = rvalue.Interface()
+		override = goVirtual(class, name, ftype)
+		method   = internVirtual(name)
+	)
+	rvalue.Set(reflect.MakeFunc(ftype, func(args []reflect.Value) []reflect.Value {
+		var self = class.AsObject()
+		if self == ([1]gd.Object{}) || !self[0].HasMethod(method) {
+			if override.IsValid() {
+				return override.Call(args)
+			}
+			if fallback := reflect.ValueOf(fallback); !fallback.IsNil() {
+				return fallback.Call(args)
+			}
+			return zeroResults(ftype)
+		}
+		var variants = make([]gd.Variant, len(args))
+		for i, arg := range args {
+			variants[i] = gd.NewVariant(arg.Interface())
+		}
+		result, err := self[0].Call(method, variants...)
+		if err != nil {
+			EngineClass.Raise(fmt.Errorf("%s: %w", name, err))
+			return zeroResults(ftype)
+		}
+		if ftype.NumOut() == 0 {
+			return nil
+		}
+		converted, err := gd.ConvertToDesiredGoType(result, ftype.Out(0))
+		if err != nil {
+			EngineClass.Raise(fmt.Errorf("%s: %w", name, err))
+			return zeroResults(ftype)
+		}
+		return []reflect.Value{converted}
+	}))
+}
+
+// goVirtual returns the method of the Go class that overrides the virtual method with the given
+// name, if there is one. Methods that do not match the signature of the virtual method are ignored,
+// they are reported by [registerVirtuals].
+func goVirtual(class gdclass.Pointer, name string, ftype reflect.Type) reflect.Value {
+	GoName := convertName(name)
+	method, ok := reflect.TypeOf(class).MethodByName(GoName)
+	if !ok {
+		return reflect.Value{}
+	}
+	bound := reflect.ValueOf(class).Method(method.Index)
+	if bound.Type() != ftype {
+		return reflect.Value{}
+	}
+	return bound
+}
+
+func zeroResults(ftype reflect.Type) []reflect.Value {
+	var results = make([]reflect.Value, ftype.NumOut())
+	for i := range results {
+		results[i] = reflect.Zero(ftype.Out(i))
+	}
+	return results
+}
@@ -66,7 +66,43 @@ func (linux Linux) BuildMain(args ...string) error {
 	return nil
 }
 
-func (linux Linux) Run(args ...string) error {
+// BuildServer exports a headless dedicated server, the engine will use dummy display and audio
+// drivers and any resources marked as server-side only will be stripped.
+func (linux Linux) BuildServer(args ...string) error {
+	var GOARCH = runtime.GOARCH
+	if goarch := os.Getenv("GOARCH"); goarch != "" {
+		GOARCH = goarch
+	}
+	if err := linux.Build(args...); err != nil {
+		return xray.New(err)
+	}
+	var export []string
+	switch GOARCH {
+	case "amd64":
+		export = []string{"--headless", "--export-release", "Linux x86_64 Server"}
+	case "arm64":
+		export = []string{"--headless", "--export-release", "Linux arm64 Server"}
+	default:
+		return fmt.Errorf("gd export: cannot export linux %v server", GOARCH)
+	}
+	if err := os.Chdir(project.GraphicsDirectory); err != nil {
+		return xray.New(err)
+	}
+	if err := tooling.Godot.Exec(export...); err != nil {
+		return xray.New(err)
+	}
+	return nil
+}
+
+func (linux Linux) Run(args ...string) error { return linux.run(nil, args...) }
+
+// RunServer runs the project as a dedicated server, the engine is started with --headless so that
+// it uses dummy display and audio drivers, just like an exported server.
+func (linux Linux) RunServer(args ...string) error {
+	return linux.run([]string{"--headless"}, args...)
+}
+
+func (linux Linux) run(engine []string, args ...string) error {
 	var GOARCH = runtime.GOARCH
 	if goarch := os.Getenv("GOARCH"); goarch != "" {
 		GOARCH = goarch
@@ -80,7 +116,7 @@ func (linux Linux) Run(args ...string) error {
 	if err := os.Chdir(project.GraphicsDirectory); err != nil {
 		return xray.New(err)
 	}
-	return tooling.Godot.Exec()
+	return tooling.Godot.Exec(engine...)
 }
 
 func (Linux) Test(args ...string) error {
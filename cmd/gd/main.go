@@ -16,6 +16,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 
 	"graphics.gd/cmd/gd/internal/builder"
@@ -42,6 +43,14 @@ type Builder interface {
 	Test(...string) error      // go test
 }
 
+// ServerBuilder is implemented by builders that can export and run a headless dedicated server,
+// which is currently only the case for Linux, 'gd build -server' and 'gd run -server' fail with
+// an error on any other GOOS.
+type ServerBuilder interface {
+	BuildServer(...string) error // go build -buildmode=c-shared + dedicated server export
+	RunServer(...string) error   // go build -buildmode=c-shared + run the engine with --headless
+}
+
 func builderFor(goos string) Builder {
 	switch goos {
 	case "linux", "ubuntu", "arch", "debian", "nix":
@@ -107,6 +116,12 @@ func gd(args ...string) error {
 			}
 		}
 	}
+	if i := slices.Index(args, "-pointercheck"); i >= 0 {
+		args = slices.Delete(args, i, i+1) // record pointer stacks and report leaks at shutdown.
+		if err := os.Setenv("GDPOINTERCHECK", "1"); err != nil {
+			return xray.New(err)
+		}
+	}
 	switch len(args) {
 	case 1:
 		if err := platform.Build(); err != nil {
@@ -122,12 +137,26 @@ func gd(args ...string) error {
 			if err := os.MkdirAll(filepath.Join(project.ReleasesDirectory, GOOS, GOARCH), 0755); err != nil {
 				return xray.New(err)
 			}
+			if i := slices.Index(args[2:], "-server"); i >= 0 {
+				server, ok := platform.(ServerBuilder)
+				if !ok {
+					return fmt.Errorf("gd build -server: dedicated servers are not supported for GOOS=%v", GOOS)
+				}
+				return server.BuildServer(slices.Delete(args[2:], i, i+1)...)
+			}
 			return platform.BuildMain(args[2:]...)
 		case "run":
+			if i := slices.Index(args[2:], "-server"); i >= 0 {
+				server, ok := platform.(ServerBuilder)
+				if !ok {
+					return fmt.Errorf("gd run -server: dedicated servers are not supported for GOOS=%v", GOOS)
+				}
+				return server.RunServer(slices.Delete(args[2:], i, i+1)...)
+			}
 			return platform.Run(args[2:]...)
 		case "test":
 			converted := []string{}
-			for _, arg := range os.Args[2:] {
+			for _, arg := range args[2:] {
 				switch arg {
 				case "-bench", "-benchmem", "-benchtime", "blockprofile",
 					"-blockprofilerate", "-count", "-coverprofile", "-cpu",
@@ -143,6 +172,18 @@ func gd(args ...string) error {
 				}
 			}
 			return platform.Test(converted...)
+		case "generate":
+			if len(args) < 3 || args[2] != "shaders" {
+				return tooling.Go.Exec(args...)
+			}
+			// run the program without the engine, so that it writes the shaders and exits.
+			if err := os.Setenv("GDGENERATE", "shaders"); err != nil {
+				return xray.New(err)
+			}
+			if err := os.Setenv("GDGENERATE_DIR", filepath.Join(project.GraphicsDirectory, "shaders")); err != nil {
+				return xray.New(err)
+			}
+			return tooling.Go.Exec(append([]string{"run", "."}, args[3:]...)...)
 		default:
 			return tooling.Go.Exec(args...)
 		}
@@ -23,3 +23,7 @@ var StartupFunctions []func()
 var PostStartupFunctions []func()
 
 var EditorStartupFunctions []func()
+
+// Generators write files into the given directory for the 'gd generate' command, they are keyed
+// by the kind of files that they generate (ie. "shaders") and run without the engine.
+var Generators = make(map[string]func(dir string) error)
@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	"graphics.gd/internal/gdextension"
 	"graphics.gd/internal/pointers"
@@ -23,37 +24,84 @@ func NewStringName(s string) StringName {
 	return pointers.New[StringName](gdextension.Host.Strings.Intern.UTF8(s))
 }
 
-var traceALL = os.Getenv("GOTRACEBACK") == "all" || os.Getenv("GOTRACEBACK") == "1"
-var traceSystem = os.Getenv("GOTRACEBACK") == "system"
 var traceCrash = os.Getenv("GOTRACEBACK") == "crash"
 
+// PanicPolicy determines what happens when Go code called by the engine panics.
+type PanicPolicy int
+
+const (
+	PanicContinue PanicPolicy = iota // log the panic and continue.
+	PanicQuit                        // log the panic and quit gracefully.
+	PanicCrash                       // crash the process.
+)
+
+var (
+	// OnPanic is the policy applied by [Recover], defaults to [PanicCrash] when GOTRACEBACK=crash.
+	OnPanic = func() PanicPolicy {
+		if traceCrash {
+			return PanicCrash
+		}
+		return PanicContinue
+	}()
+	// CrashReport, if not empty, is the path of a file that a crash report is written to whenever
+	// a panic is recovered.
+	CrashReport string
+	// Quit is called to quit gracefully, under the [PanicQuit] policy.
+	Quit func()
+)
+
+// Recover should be deferred at each boundary where the engine calls into Go, so that panics
+// are handled according to the [OnPanic] policy.
 func Recover() {
-	if !traceCrash {
-		if err := recover(); err != nil {
-			recovery(err)
+	if err := recover(); err != nil {
+		stack := debug.Stack()
+		if CrashReport != "" {
+			writeCrashReport(err, stack)
+		}
+		if OnPanic == PanicCrash {
+			panic(err)
+		}
+		recovery(err, stack)
+		if OnPanic == PanicQuit && Quit != nil {
+			Quit()
 		}
 	}
 }
 
-func recovery(err any) {
-	if traceALL || traceSystem {
-		gdextension.Host.Log.Error(fmt.Sprint(err, "\n", string(debug.Stack())), "", "gdextension.recovery", "err.go", 18, true)
-	} else {
-		name, file, line := "", "", 0
-		var buf [10]uintptr
-		for i := range runtime.Callers(0, buf[:]) {
-			pc := buf[i]
-			if pc == 0 {
-				break
-			}
-			fn := runtime.FuncForPC(pc)
-			name = fn.Name()
-			if strings.HasPrefix(name, "runtime.") || strings.HasPrefix(name, "graphics.gd") {
-				continue
-			}
-			file, line = fn.FileLine(pc)
+func recovery(err any, stack []byte) {
+	name, file, line := "", "", 0
+	var buf [32]uintptr
+	for i := range runtime.Callers(0, buf[:]) {
+		pc := buf[i]
+		if pc == 0 {
 			break
 		}
-		gdextension.Host.Log.Error(fmt.Sprint(err), "", name, file, int32(line), true)
+		fn := runtime.FuncForPC(pc)
+		name = fn.Name()
+		if strings.HasPrefix(name, "runtime.") || strings.HasPrefix(name, "graphics.gd") {
+			continue
+		}
+		file, line = fn.FileLine(pc)
+		break
+	}
+	gdextension.Host.Log.Error(fmt.Sprint(err, "\n", string(stack)), "", name, file, int32(line), true)
+}
+
+// writeCrashReport writes the panic, along with the stacks of all goroutines and the engine version
+// to the [CrashReport] file.
+func writeCrashReport(err any, stack []byte) {
+	var all = make([]byte, 1<<20)
+	all = all[:runtime.Stack(all, true)]
+	var report strings.Builder
+	fmt.Fprintf(&report, "panic: %v\n\n", err)
+	fmt.Fprintf(&report, "time: %s\n", time.Now().Format(time.RFC3339))
+	if Linked {
+		fmt.Fprintf(&report, "engine: %d.%d.%d\n", gdextension.Host.Version.Major(), gdextension.Host.Version.Minor(), gdextension.Host.Version.Patch())
+	}
+	fmt.Fprintf(&report, "go: %s %s/%s\n\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&report, "%s\n", stack)
+	fmt.Fprintf(&report, "goroutines:\n\n%s", all)
+	if err := os.WriteFile(CrashReport, []byte(report.String()), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "graphics.gd: cannot write crash report:", err)
 	}
 }
@@ -40,6 +40,10 @@ func GetObject(class Interface) [1]gd.Object {
 
 var Registered sync.Map
 
+// Declared holds each class type passed to classdb.Register, in order, including those that are
+// waiting for the engine to be linked.
+var Declared []reflect.Type
+
 type Constructor interface {
 	CreateGoInstanceFrom(reflect.Value, bool) [1]gd.Object
 }
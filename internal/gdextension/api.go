@@ -320,6 +320,7 @@ type API struct {
 		Register struct {
 			Class            func(class, parent_class StringName, id ExtensionClassID, virtual, abstract, exposed, runtime bool, icon_path String) `gd:"classdb_register"`
 			Methods          func(class StringName, methods MethodList)                                                                            `gd:"classdb_register_methods"`
+			Virtual          func(class, method StringName, flags MethodFlags, result, args PropertyList)                                          `gd:"classdb_register_virtual"`
 			Constant         func(class, enum, name StringName, value int64, bitfield bool)                                                        `gd:"classdb_register_constant"`
 			Property         func(class StringName, property PropertyList, setter, getter StringName)                                              `gd:"classdb_register_property"`
 			PropertyIndexed  func(class StringName, property PropertyList, setter, getter StringName, index int)                                   `gd:"classdb_register_property_indexed"`
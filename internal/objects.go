@@ -15,6 +15,9 @@ type ExtensionClassCallVirtualFunc func(any, gdextension.Pointer, gdextension.Po
 
 var ExtensionInstanceLookup func(gdextension.Object) any
 
+// ThreadCheck, if set, is called before each engine call made on an object.
+var ThreadCheck func()
+
 type NotificationType int32
 
 func PointerWithOwnershipTransferredToGo[T pointers.Generic[T, [3]uint64]](ptr gdextension.Object) T {
@@ -73,6 +76,9 @@ func CallerIncrements(obj [1]Object) gdextension.Object {
 }
 
 func ObjectChecked(obj [1]Object) gdextension.Object {
+	if ThreadCheck != nil {
+		ThreadCheck()
+	}
 	raw := pointers.Get(obj[0])
 	if !obj[0].IsAlive(raw) {
 		panic("use after free")
@@ -0,0 +1,163 @@
+package pointers
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// diagnostics is enabled by setting the GDPOINTERCHECK environment variable to 1, such that the
+// allocation and release stacks of each pointer are recorded, so that misuse panics can explain
+// where the pointer came from and so that leaks can be reported with [ReportLeaks].
+var diagnostics = os.Getenv("GDPOINTERCHECK") == "1"
+
+// slot identifies the location of a pointer within the [tables].
+type slot struct {
+	shape int
+	index uint64
+}
+
+// record of a pointer allocation, for diagnostic purposes.
+type record struct {
+	rtype    reflect.Type
+	revision revision
+	created  []uintptr
+	released []uintptr
+}
+
+var records struct {
+	sync.Mutex
+	live     map[slot]record
+	released map[slot]record // only the most recent release for each slot is kept.
+}
+
+func callers() []uintptr {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	return slices.Clone(pcs[:n])
+}
+
+// track records the allocation of a pointer.
+func track(rtype reflect.Type, shape int, index uint64, rev revision) {
+	records.Lock()
+	defer records.Unlock()
+	if records.live == nil {
+		records.live = make(map[slot]record)
+		records.released = make(map[slot]record)
+	}
+	records.live[slot{shape, index}] = record{rtype: rtype, revision: rev, created: callers()}
+}
+
+// untrack records the release of a pointer.
+func untrack(shape int, index uint64) {
+	records.Lock()
+	defer records.Unlock()
+	key := slot{shape, index}
+	if rec, ok := records.live[key]; ok {
+		rec.released = callers()
+		records.released[key] = rec
+		delete(records.live, key)
+	}
+}
+
+// invalid returns the message to panic with when the pointer with the given shape, index and
+// revision is used after it has been released.
+func invalid(shape int, index uint64, rev revision) string {
+	if !diagnostics {
+		return panicMessage
+	}
+	records.Lock()
+	defer records.Unlock()
+	if rec, ok := records.live[slot{shape, index}]; ok && rec.revision.matches(rev) {
+		var msg strings.Builder
+		fmt.Fprintf(&msg, "%s\n\n%v was freed by the engine, it was created at:\n\n", panicMessage, rec.rtype)
+		writeStack(&msg, rec.created)
+		return msg.String()
+	}
+	rec, ok := records.released[slot{shape, index}]
+	if !ok || !rec.revision.matches(rev) {
+		return panicMessage + "\n\n(no record of where this pointer was created or released)"
+	}
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "%s\n\n%v was created at:\n\n", panicMessage, rec.rtype)
+	writeStack(&msg, rec.created)
+	fmt.Fprintf(&msg, "\nand was released at:\n\n")
+	writeStack(&msg, rec.released)
+	return msg.String()
+}
+
+func writeStack(w io.Writer, pcs []uintptr) {
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(w, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+}
+
+// callsite returns the first frame of the stack outside of the graphics.gd internals.
+func callsite(pcs []uintptr) string {
+	var first string
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		site := fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line)
+		if first == "" {
+			first = site
+		}
+		if !strings.HasPrefix(frame.Function, "graphics.gd/internal") {
+			return site
+		}
+		if !more {
+			return first
+		}
+	}
+}
+
+// ReportLeaks writes a report of any pointers that are still live, grouped by type and call site,
+// if GDPOINTERCHECK=1 is set. It should be called at shutdown, after all pointers are expected to
+// have been released. Returns the number of leaked pointers.
+func ReportLeaks(w io.Writer) int {
+	if !diagnostics {
+		return 0
+	}
+	records.Lock()
+	defer records.Unlock()
+	type group struct {
+		rtype reflect.Type
+		site  string
+		count int
+	}
+	var groups = make(map[string]*group)
+	for _, rec := range records.live {
+		site := callsite(rec.created)
+		key := rec.rtype.String() + " " + site
+		if groups[key] == nil {
+			groups[key] = &group{rtype: rec.rtype, site: site}
+		}
+		groups[key].count++
+	}
+	if len(groups) == 0 {
+		return 0
+	}
+	var sorted []*group
+	for _, g := range groups {
+		sorted = append(sorted, g)
+	}
+	slices.SortFunc(sorted, func(a, b *group) int {
+		return cmp.Or(b.count-a.count, strings.Compare(a.rtype.String(), b.rtype.String()), strings.Compare(a.site, b.site))
+	})
+	fmt.Fprintf(w, "graphics.gd: %d leaked pointers:\n", len(records.live))
+	for _, g := range sorted {
+		fmt.Fprintf(w, "\t%d x %v created by %s\n", g.count, g.rtype, g.site)
+	}
+	return len(records.live)
+}
@@ -0,0 +1,32 @@
+package pointers
+
+import (
+	"strings"
+	"testing"
+)
+
+type diagnosed Type[diagnosed, [1]uint64]
+
+func (ptr diagnosed) Free() { End(ptr) }
+
+func TestDiagnostics(t *testing.T) {
+	diagnostics = true
+	defer func() { diagnostics = false }()
+
+	leaked := New[diagnosed]([1]uint64{1})
+	defer End(leaked)
+	var report strings.Builder
+	if n := ReportLeaks(&report); n == 0 || !strings.Contains(report.String(), "pointers.diagnosed") {
+		t.Fatalf("expected leak to be reported, got %d:\n%s", n, report.String())
+	}
+
+	freed := New[diagnosed]([1]uint64{2})
+	End(freed)
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "was created at") || !strings.Contains(msg, "was released at") {
+			t.Fatalf("expected creation and release stacks, got:\n%s", msg)
+		}
+	}()
+	Get(freed)
+}
@@ -36,6 +36,9 @@
 //
 // The [For] function returns an iterator for all pointers of a given type, this can be used to free all pointers of a given type.
 // Up to 16 pointer types for each shape are currently supported.
+//
+// Set GDPOINTERCHECK=1 to record where each pointer was created and released, such that misuse
+// panics include these stacks and so that leaks can be reported at shutdown with [ReportLeaks].
 package pointers
 
 import (
@@ -222,6 +225,31 @@ func Cycle() {
 	}
 }
 
+// Count returns the number of pointers that are currently live, along with how many of
+// those are pinned.
+func Count() (live, pinned int) {
+	for s := range shapesMax {
+		tab := &tables[s]
+		for j := range tab.len.Load() {
+			page := tab.Index(j)
+			for i := uint64(0); i < pageSize; i += uint64(s + 2) {
+				rev := revision(page[i+offsetRevision].Load())
+				if rev == revisionEOF {
+					break // end of the table.
+				}
+				if rev.isClosed() {
+					continue
+				}
+				live++
+				if rev.isPinned() {
+					pinned++
+				}
+			}
+		}
+	}
+	return
+}
+
 // New manages the given pointer value discretely.
 func New[T Generic[T, P], P Size](ptr P) T {
 	return malloc(ptr, T.Free)
@@ -276,6 +304,9 @@ func malloc[T Generic[T, P], P Size](ptr P, free func(T)) T {
 			}
 			current.revision = rev
 			current.checksum = ptr
+			if diagnostics {
+				track(reflect.TypeFor[T](), len(ptr), idx, rev)
+			}
 			return T(current)
 		}
 	}
@@ -298,6 +329,9 @@ func end(rev revision, s int, p uint64) bool {
 				arr[addr+offsetPointers].Store(end)
 				if writes[s].CompareAndSwap(end, p) {
 					arr[addr+offsetRevision].Store(uint64(existing.close())) // next free.
+					if diagnostics {
+						untrack(s, p)
+					}
 					return true
 				}
 			}
@@ -350,11 +384,11 @@ func Get[T Generic[T, P], P Size](ptr T) P {
 		}
 		if !rev.matches(p.revision) {
 			//fmt.Printf("%b != %b\b", rev&0b00111111111111111111111111111111111111111111111111111111111111, p.revision&0b00111111111111111111111111111111111111111111111111111111111111)
-			panic(panicMessage)
+			panic(invalid(len(p.checksum), p.sentinal, p.revision))
 		}
 		if !rev.isActive() {
 			if live, ok := any(T(p)).(Liveness[P]); ok && !live.IsAlive(*(*P)(unsafe.Pointer(&ptrs))) {
-				panic(panicMessage)
+				panic(invalid(len(p.checksum), p.sentinal, p.revision))
 			}
 			arr[addr+offsetRevision].CompareAndSwap(uint64(rev), uint64(rev.active()))
 		}
@@ -436,7 +470,7 @@ func Set[T Generic[T, P], P Size](ptr T, val P) {
 	for {
 		rev := revision(arr[addr+offsetRevision].Load())
 		if !rev.matches(p.revision) {
-			panic(panicMessage)
+			panic(invalid(len(p.checksum), p.sentinal, p.revision))
 		}
 		if rev != revisionLocked && arr[addr+offsetRevision].CompareAndSwap(uint64(rev), revisionLocked) {
 			var local [3]uint64
@@ -511,7 +545,7 @@ func Pin[T Generic[T, P], P Size](ptr T) T {
 	arr := tables[len(p.checksum)].Index(page)
 	rev := revision(arr[addr+offsetRevision].Load())
 	if !rev.matches(p.revision) {
-		panic(panicMessage)
+		panic(invalid(len(p.checksum), p.sentinal, p.revision))
 	}
 	arr[addr+offsetRevision].CompareAndSwap(uint64(rev), uint64(rev.pinned()))
 	return ptr
@@ -556,7 +590,7 @@ func Lay[T Generic[T, P], P Size](ptr T) T {
 	for {
 		rev := revision(arr[addr+offsetRevision].Load())
 		if !rev.matches(p.revision) {
-			panic(panicMessage)
+			panic(invalid(len(p.checksum), p.sentinal, p.revision))
 		}
 		if rev != revisionLocked && arr[addr+offsetRevision].CompareAndSwap(uint64(rev), revisionLocked) {
 			arr[addr+offsetFreeFunc].Store(0)
@@ -695,11 +729,11 @@ func Ask[T Generic[T, P], P Size](ptr T) (P, Kind) {
 		}
 		if !rev.matches(p.revision) {
 			//fmt.Printf("%b != %b\b", rev&0b00111111111111111111111111111111111111111111111111111111111111, p.revision&0b00111111111111111111111111111111111111111111111111111111111111)
-			panic(panicMessage)
+			panic(invalid(len(p.checksum), p.sentinal, p.revision))
 		}
 		if !rev.isActive() {
 			if live, ok := any(T(p)).(Liveness[P]); ok && !live.IsAlive(*(*P)(unsafe.Pointer(&ptrs))) {
-				panic(panicMessage)
+				panic(invalid(len(p.checksum), p.sentinal, p.revision))
 			}
 			arr[addr+offsetRevision].CompareAndSwap(uint64(rev), uint64(rev.active()))
 		}
@@ -49,3 +49,16 @@ func TestPointersV2(t *testing.T) {
 		t.Fatal("simulated pointers not freed")
 	}
 }
+
+func TestCount(t *testing.T) {
+	before, pinnedBefore := pointers.Count()
+	p := pointers.Pin(pointers.New[MyString]([1]uint64{2}))
+	live, pinned := pointers.Count()
+	if live != before+1 || pinned != pinnedBefore+1 {
+		t.Fatal("unexpected counts", live, pinned)
+	}
+	pointers.End(p)
+	if live, _ := pointers.Count(); live != before {
+		t.Fatal("unexpected live count after End", live)
+	}
+}
@@ -7,12 +7,16 @@ import (
 	"testing"
 
 	"graphics.gd/classdb"
+	"graphics.gd/classdb/CharacterBody2D"
+	"graphics.gd/classdb/ClassDB"
 	"graphics.gd/classdb/Engine"
 	"graphics.gd/classdb/Node"
 	"graphics.gd/classdb/Node2D"
+	"graphics.gd/classdb/SceneTree"
 	gd "graphics.gd/internal"
 	"graphics.gd/internal/gdextension"
 	"graphics.gd/internal/pointers"
+	"graphics.gd/variant/Object"
 )
 
 func TestRegister(t *testing.T) {
@@ -63,3 +67,219 @@ func TestSingleton(t *testing.T) {
 	classdb.Register[TestingSingleton]()
 	Engine.RegisterSingleton("HelloWorld", new(TestingSingleton).AsObject())
 }
+
+type TestingPlayer struct {
+	CharacterBody2D.Extension[TestingPlayer]
+}
+
+type TestingLevel struct {
+	Node.Extension[TestingLevel]
+
+	Body   CharacterBody2D.Instance `node:"Body"`
+	Player *TestingPlayer           `node:"Body"`
+}
+
+func TestNodeTagSubclass(t *testing.T) {
+	classdb.Register[TestingPlayer]()
+	classdb.Register[TestingLevel]()
+
+	player := new(TestingPlayer)
+	player.AsNode().SetName("Body")
+	level := new(TestingLevel)
+	level.AsNode().AddChild(player.AsNode())
+
+	tree, ok := Object.As[SceneTree.Instance](Engine.GetMainLoop())
+	if !ok {
+		t.Skip("no scene tree")
+	}
+	tree.Root().AsNode().AddChild(level.AsNode())
+	defer level.AsNode().QueueFree()
+	if level.Player != player {
+		t.Fatal("Go typed node field was not resolved to the Go instance")
+	}
+	if level.Body == CharacterBody2D.Nil || level.Body.AsNode().Name() != "Body" {
+		t.Fatal("native node field was not resolved to the Go subclass")
+	}
+}
+
+type TestingListener struct {
+	Node.Extension[TestingListener]
+
+	Renamed func() `connect:"Child.renamed"`
+}
+
+func TestConnectReparent(t *testing.T) {
+	classdb.Register[TestingListener]()
+
+	tree, ok := Object.As[SceneTree.Instance](Engine.GetMainLoop())
+	if !ok {
+		t.Skip("no scene tree")
+	}
+	var renamed int
+	listener := new(TestingListener)
+	listener.Renamed = func() { renamed++ }
+	child := Node.New()
+	child.SetName("Child")
+	listener.AsNode().AddChild(child)
+	defer listener.AsNode().QueueFree()
+
+	root := tree.Root().AsNode()
+	root.AddChild(listener.AsNode())
+	child.SetName("Renamed1")
+	child.SetName("Child")
+	if renamed != 2 {
+		t.Fatalf("expected 2 renames after entering the tree, got %d", renamed)
+	}
+	root.RemoveChild(listener.AsNode())
+	child.SetName("Renamed2")
+	child.SetName("Child")
+	if renamed != 2 {
+		t.Fatalf("expected the signal to be disconnected after exiting the tree, got %d renames", renamed)
+	}
+	parent := Node.New()
+	root.AddChild(parent)
+	defer parent.QueueFree()
+	parent.AddChild(listener.AsNode())
+	child.SetName("Renamed3")
+	if renamed != 3 {
+		t.Fatalf("expected the signal to be connected once after re-parenting, got %d renames", renamed)
+	}
+}
+
+func TestContextCancelled(t *testing.T) {
+	classdb.Register[TestingListener]()
+
+	tree, ok := Object.As[SceneTree.Instance](Engine.GetMainLoop())
+	if !ok {
+		t.Skip("no scene tree")
+	}
+	listener := new(TestingListener)
+	root := tree.Root().AsNode()
+	root.AddChild(listener.AsNode())
+	ctx := classdb.Context(listener)
+	if ctx.Err() != nil {
+		t.Fatal("expected the context to be live while in the tree")
+	}
+	root.RemoveChild(listener.AsNode())
+	if ctx.Err() == nil {
+		t.Fatal("expected the context to be cancelled after exiting the tree")
+	}
+	ctx = classdb.Context(listener)
+	if ctx.Err() != nil {
+		t.Fatal("expected a fresh context after exiting the tree")
+	}
+	listener.AsObject()[0].Free()
+	if ctx.Err() == nil {
+		t.Fatal("expected the context to be cancelled after the instance was freed")
+	}
+	if classdb.Context(listener).Err() == nil {
+		t.Fatal("expected the context of a freed instance to be cancelled")
+	}
+}
+
+type TestingWeapon struct {
+	Node.Extension[TestingWeapon]
+
+	OnFire func(power int) int `virtual:"_on_fire(power)"`
+}
+
+func (w *TestingWeapon) Fire(power int) int { return w.OnFire(power) }
+
+type TestingCannon struct {
+	TestingWeapon
+}
+
+func (c *TestingCannon) OnFire(power int) int { return power * 2 }
+
+func TestVirtualGoSubclass(t *testing.T) {
+	classdb.Register[TestingWeapon]()
+	classdb.Register[TestingCannon]()
+
+	weapon := new(TestingWeapon)
+	defer weapon.AsNode().QueueFree()
+	if result := weapon.Fire(3); result != 0 {
+		t.Fatalf("expected the zero value from the base class, got %d", result)
+	}
+	cannon := new(TestingCannon)
+	defer cannon.AsNode().QueueFree()
+	if result := cannon.Fire(3); result != 6 {
+		t.Fatalf("expected the Go subclass override to be called, got %d", result)
+	}
+}
+
+type TestingShape struct {
+	Node2D.Extension[TestingShape]
+	classdb.Abstract
+}
+
+type TestingCircle struct {
+	TestingShape
+
+	Radius float64
+}
+
+func TestAbstract(t *testing.T) {
+	classdb.Register[TestingShape]()
+	classdb.Register[TestingCircle]()
+
+	if ClassDB.CanInstantiate("TestingShape") {
+		t.Fatal("abstract class can be instantiated")
+	}
+	if !ClassDB.CanInstantiate("TestingCircle") {
+		t.Fatal("subclass of an abstract class cannot be instantiated")
+	}
+	value, ok := ClassDB.Instantiate("TestingCircle").(gd.IsClass)
+	if !ok {
+		t.Fatal("subclass of an abstract class was not instantiated")
+	}
+	node, ok := Object.As[Node2D.Instance](value)
+	if !ok {
+		t.Fatal("subclass of an abstract class was not built on its Node2D base")
+	}
+	defer node.AsNode().QueueFree()
+	if class := node.AsObject()[0].GetClass().String(); class != "TestingCircle" {
+		t.Fatalf("expected a TestingCircle, got %s", class)
+	}
+	if _, ok := Object.As[*TestingCircle](value); !ok {
+		t.Fatal("instance is not backed by the Go subclass")
+	}
+}
+
+type TestingMigratedV1 struct {
+	Node.Extension[TestingMigratedV1] `gd:"TestingMigrated"`
+
+	Health int
+}
+
+type TestingMigratedV2 struct {
+	Node.Extension[TestingMigratedV2] `gd:"TestingMigrated"`
+
+	Health int
+	Armor  int
+}
+
+func (v2 *TestingMigratedV2) OnMigrate(old any) {
+	v2.Armor = old.(*TestingMigratedV1).Health / 2
+}
+
+func TestMigration(t *testing.T) {
+	classdb.Register[TestingMigratedV1]()
+	v1 := new(TestingMigratedV1)
+	v1.Health = 42
+	node := v1.AsNode()
+	defer node.QueueFree()
+	if err := classdb.Unregister[TestingMigratedV1](); err != nil {
+		t.Fatal(err)
+	}
+	if class := node.AsObject()[0].GetClass().String(); class != "TestingMigrated" {
+		t.Fatalf("expected a TestingMigrated placeholder, got %s", class)
+	}
+	classdb.Register[TestingMigratedV2]()
+	v2, ok := Object.As[*TestingMigratedV2](node)
+	if !ok {
+		t.Fatal("instance was not migrated to the new type")
+	}
+	if v2.Health != 42 || v2.Armor != 21 {
+		t.Fatalf("unexpected migrated values %d %d", v2.Health, v2.Armor)
+	}
+}
@@ -0,0 +1,95 @@
+package shaders
+
+import (
+	"reflect"
+	"strings"
+
+	"graphics.gd/shaders/int"
+	"graphics.gd/shaders/internal/gpu"
+)
+
+// Var declares a local variable on the GPU, initialized to the given value, the returned value
+// refers to the variable and can be updated with [Assign]. Use variables to carry values out of
+// an [If] or [Loop] block. A variable is only in scope within the block it was declared in.
+//
+// Reading the variable itself always sees its latest value, whereas values derived from it keep
+// the value it had when they were derived, as they are stored into a local of their own (which,
+// like the variable, is only in scope within the block it was derived in).
+//
+//	color := shaders.Var(vec4.New(0, 0, 0, 1))
+//	shaders.If(float.Gt(dist, 1), func() {
+//		shaders.Assign(&color, vec4.New(1, 0, 0, 1))
+//	}, nil)
+func Var[T gpu.Evaluator](value T) T {
+	name := gpu.Variable("shaders.Var")
+	gpu.Emit("shaders.Var", gpu.Declare{Name: name, Type: reflect.TypeFor[T](), Value: value})
+	var variable T
+	identify(reflect.ValueOf(&variable).Elem(), name)
+	return variable
+}
+
+// Assign a new value to a variable declared with [Var].
+func Assign[T gpu.Evaluator](variable *T, value T) {
+	name, ok := gpu.Evaluate(*variable).(gpu.Identifier)
+	if !ok || !strings.HasPrefix(string(name), "_v") {
+		panic("shaders.Assign: variable was not declared with shaders.Var")
+	}
+	gpu.Emit("shaders.Assign", gpu.Assign{Name: name, Value: value})
+}
+
+// If runs then on the GPU when the condition is true, otherwise it runs otherwise (which may be
+// nil). Unlike a Go if statement, which is evaluated once when the shader is compiled, the
+// condition is evaluated for each vertex/pixel.
+func If[T gpu.AnyBool](cond T, then, otherwise func()) {
+	gpu.Emit("shaders.If", gpu.If{
+		Cond: gpu.NewBool(cond),
+		Then: gpu.Block("shaders.If", false, then),
+		Else: gpu.Block("shaders.If", false, otherwise),
+	})
+}
+
+// Loop runs body on the GPU count times, passing the index of each iteration (counting up from
+// zero). The count does not need to be a constant, so it can be driven by a uniform. Use [Break]
+// and [Continue] to leave the loop early.
+//
+//	shaders.Loop(shader.Steps, func(i int.X) {
+//		shaders.If(float.Lt(dist, 0.001), shaders.Break, nil)
+//		...
+//	})
+func Loop[T gpu.AnyInt](count T, body func(i int.X)) {
+	index := gpu.Local("shaders.Loop")
+	gpu.Emit("shaders.Loop", gpu.Loop{
+		Index: index,
+		Count: gpu.NewInt(count),
+		Body: gpu.Block("shaders.Loop", true, func() {
+			body(gpu.NewIntExpression(gpu.New(index)))
+		}),
+	})
+}
+
+// Break out of the innermost [Loop].
+func Break() { gpu.Emit("shaders.Break", gpu.Break{}) }
+
+// Continue with the next iteration of the innermost [Loop].
+func Continue() { gpu.Emit("shaders.Continue", gpu.Continue{}) }
+
+// Discard the current pixel, such that nothing is drawn for it. Only available within the Material
+// stage of a pipeline.
+func Discard() { gpu.Emit("shaders.Discard", gpu.Discard{}) }
+
+// identify the value as the given GLSL identifier, including any vector components.
+func identify(value reflect.Value, name gpu.Identifier) {
+	gpu.Set(value.Addr().Interface().(gpu.Pointer), name)
+	if value.Kind() != reflect.Struct {
+		return
+	}
+	for _, component := range []string{"X", "Y", "Z", "W", "R", "G", "B", "A"} {
+		field := value.FieldByName(component)
+		if !field.IsValid() {
+			continue
+		}
+		if ptr, ok := field.Addr().Interface().(gpu.Pointer); ok {
+			gpu.Set(ptr, name+gpu.Identifier("."+strings.ToLower(component)))
+		}
+	}
+}
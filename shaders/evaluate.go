@@ -0,0 +1,142 @@
+package shaders
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"graphics.gd/shaders/internal/cpu"
+	"graphics.gd/shaders/internal/gpu"
+)
+
+// Pixel holds the outputs of the Material and Lighting stages of a shader for a single pixel, as
+// returned by [Evaluate]. Outputs are keyed by their GLSL built-in name and hold the Go value that
+// is equivalent to their GPU type, such as a Color.RGBA for a vec4.RGBA. A stage that the shader
+// does not implement has no outputs.
+type Pixel struct {
+	Material map[string]any
+	Lighting map[string]any
+}
+
+// ErrDiscarded is returned by [Evaluate] when the Material stage discards the pixel.
+var ErrDiscarded = errors.New("shaders.Evaluate: pixel was discarded")
+
+// Evaluate runs the Material and Lighting stages of the shader on the CPU, for a single pixel,
+// with the same float32 precision as the GPU. The inputs are keyed by GLSL name and provide the
+// built-ins read by the shader (such as UV or LIGHT_COLOR) along with any uniforms, uniforms that
+// are not provided use their default value (or zero). Inputs are the Go values that are equivalent
+// to their GPU types, samplers are provided as an image.Image and are sampled with nearest filtering.
+// The outputs of the Material stage are available to the Lighting stage.
+//
+// The engine is not required, so the math of a shader can be tested with go test on machines
+// without a GPU:
+//
+//	pixel, err := shaders.Evaluate(new(MyShader), map[string]any{
+//		"UV": Vector2.New(0.5, 0.5),
+//	})
+//
+// Derivatives (such as fwidth) are always zero and built-ins that have no CPU equivalent return
+// an error.
+func Evaluate(prog Any, inputs map[string]any) (Pixel, error) {
+	_, f, m, _ := stageTypes(prog)
+	var fragment = reflect.New(f).Elem()
+	linkup(fragment.Addr().Interface())
+	var surface = reflect.New(m).Elem()
+	linkup(surface.Addr().Interface())
+	types := make(map[string]string)
+	builtins(types, fragment.Type())
+	builtins(types, surface.Type())
+	machine := cpu.New()
+	for _, decl := range compileUniforms(io.Discard, prog) {
+		types[decl.name] = decl.glsl
+		value, err := cpu.Zero(decl.glsl)
+		if decl.value != nil {
+			value, err = machine.Eval(decl.value)
+		}
+		if err != nil {
+			return Pixel{}, fmt.Errorf("shaders.Evaluate: uniform %s: %w", decl.name, err)
+		}
+		machine.Values[decl.name] = value
+	}
+	for name, input := range inputs {
+		value, err := cpu.ValueOf(input, types[name])
+		if err != nil {
+			return Pixel{}, fmt.Errorf("shaders.Evaluate: input %s: %w", name, err)
+		}
+		machine.Values[name] = value
+	}
+	pipeline := prog.Pipeline()
+	rvalue := reflect.ValueOf(prog)
+	type stage struct {
+		result     reflect.Value
+		statements []gpu.Statement
+	}
+	var stages [2]stage
+	functions := gpu.Definitions(func() {
+		stages[0].statements = gpu.Record(true, func() {
+			stages[0].result = rvalue.MethodByName("Material").Call([]reflect.Value{fragment})[0]
+		})
+		stages[1].statements = gpu.Record(false, func() {
+			stages[1].result = rvalue.MethodByName("Lighting").Call([]reflect.Value{surface})[0]
+		})
+	})
+	for _, fn := range functions {
+		machine.Functions[fn.Name] = fn
+	}
+	var pixel Pixel
+	for i, outputs := range []*map[string]any{&pixel.Material, &pixel.Lighting} {
+		stage := stages[i]
+		if pipeline[i+1] == "" || (stage.result.IsZero() && len(stage.statements) == 0) {
+			continue
+		}
+		if err := machine.Run(stage.statements); err != nil {
+			return Pixel{}, fmt.Errorf("shaders.Evaluate: %s: %w", pipeline[i+1], err)
+		}
+		if machine.Discarded {
+			return Pixel{}, ErrDiscarded
+		}
+		*outputs = make(map[string]any)
+		if err := evaluateOutputs(machine, stage.result, *outputs); err != nil {
+			return Pixel{}, fmt.Errorf("shaders.Evaluate: %s: %w", pipeline[i+1], err)
+		}
+	}
+	return pixel, nil
+}
+
+// builtins records the GLSL type of each built-in (or varying) that is linked to the fields of the
+// stage input.
+func builtins(types map[string]string, rtype reflect.Type) {
+	stageFields(rtype, func(name string, index []int) {
+		types[name] = gpu.TypeName(rtype.FieldByIndex(index).Type)
+	})
+}
+
+// evaluateOutputs evaluates each output of the stage result in order, such that later stages
+// can read them.
+func evaluateOutputs(machine *cpu.Machine, result reflect.Value, outputs map[string]any) (err error) {
+	stageFields(result.Type(), func(name string, index []int) {
+		field := result.FieldByIndex(index)
+		if err != nil || field.IsZero() {
+			return
+		}
+		value, eerr := machine.Eval(field.Interface().(gpu.Evaluator))
+		if eerr != nil {
+			err = fmt.Errorf("%s: %w", name, eerr)
+			return
+		}
+		machine.Values[name] = value
+		equivalent, ok := gpu.EquivalentType(field.Type())
+		if !ok {
+			err = fmt.Errorf("%s: %v has no Go equivalent", name, field.Type())
+			return
+		}
+		output := reflect.New(equivalent).Elem()
+		if derr := value.Decode(output); derr != nil {
+			err = fmt.Errorf("%s: %w", name, derr)
+			return
+		}
+		outputs[name] = output.Interface()
+	})
+	return err
+}
@@ -0,0 +1,113 @@
+package shaders_test
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+
+	"graphics.gd/shaders"
+	"graphics.gd/shaders/float"
+	"graphics.gd/shaders/int"
+	"graphics.gd/shaders/pipeline/CanvasItem"
+	"graphics.gd/shaders/rgba"
+	"graphics.gd/variant/Color"
+	"graphics.gd/variant/Vector2"
+	"graphics.gd/variant/Vector3"
+)
+
+// Steps counts the iterations of a loop that are not skipped by Continue or cut short by Break.
+type Steps struct {
+	CanvasItem.Shader[Steps]
+
+	Steps int.X `gd:"steps"`
+}
+
+func (s Steps) Material(fragment CanvasItem.Fragment) CanvasItem.Material {
+	count := shaders.Var(float.New(0.0))
+	shaders.Loop(s.Steps, func(i int.X) {
+		shaders.If(int.Eq(i, 2), shaders.Continue, nil)
+		shaders.If(int.Eq(i, 5), shaders.Break, nil)
+		shaders.Assign(&count, float.Add(count, 1.0))
+	})
+	branch := shaders.Var(float.New(0.0))
+	shaders.If(float.Gt(fragment.UV.X, 0.5), func() {
+		shaders.Assign(&branch, float.New(1.0))
+	}, func() {
+		shaders.Assign(&branch, float.New(0.5))
+	})
+	return CanvasItem.Material{
+		Color: rgba.New(float.Div(count, 10.0), branch, 0.0, 1.0),
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		prog   shaders.Any
+		inputs map[string]any
+		stage  string // Material or Lighting.
+		output string
+		want   any
+	}{
+		{"solid", new(Solid), nil, "Material", "COLOR", Color.RGBA{R: 1, G: 0.5, B: 0, A: 1}},
+		{"wave", new(Wave), map[string]any{"UV": Vector2.XY{X: 0.25, Y: 0.75}}, "Material", "ALBEDO", Color.RGB{R: 0.25, G: 0.75, B: 1}},
+		{"wave", new(Wave), map[string]any{"UV": Vector2.XY{}}, "Material", "ROUGHNESS", float32(0.25)},
+		{"mist", new(Mist), map[string]any{"SDF": float32(-0.5)}, "Material", "DENSITY", float32(0.5)},
+		{"mist", new(Mist), map[string]any{"SDF": float32(-2)}, "Material", "DENSITY", float32(1)},
+		{"sky", new(Gradient), map[string]any{"EYEDIR": Vector3.XYZ{Y: 0.5}}, "Lighting", "COLOR", Color.RGB{R: 0.1, G: 0.2, B: 0.45}},
+		{"sky", new(Gradient), map[string]any{"EYEDIR": Vector3.XYZ{Y: -1}}, "Lighting", "COLOR", Color.RGB{}},
+		{"ring", new(Ring), map[string]any{"UV": Vector2.XY{X: 0.8, Y: 0.5}, "radius": float32(0.3), "mask": opaque}, "Material", "COLOR", Color.RGBA{R: 1, G: 1, B: 1, A: 1}},
+		{"ring", new(Ring), map[string]any{"UV": Vector2.XY{X: 0.5, Y: 0.5}, "radius": float32(0.3), "mask": opaque}, "Material", "COLOR", Color.RGBA{}},
+		{"loop", new(Steps), map[string]any{"steps": int32(10), "UV": Vector2.XY{X: 1}}, "Material", "COLOR", Color.RGBA{R: 0.4, G: 1, A: 1}},
+		{"loop", new(Steps), map[string]any{"steps": int32(3), "UV": Vector2.XY{}}, "Material", "COLOR", Color.RGBA{R: 0.2, G: 0.5, A: 1}},
+		{"loop", new(Steps), map[string]any{"UV": Vector2.XY{}}, "Material", "COLOR", Color.RGBA{R: 0, G: 0.5, A: 1}},
+		{"snapshot", new(Snapshot), nil, "Material", "COLOR", Color.RGBA{R: 0.5, G: 0.2, A: 1}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pixel, err := shaders.Evaluate(test.prog, test.inputs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			outputs := pixel.Material
+			if test.stage == "Lighting" {
+				outputs = pixel.Lighting
+			}
+			if got := outputs[test.output]; !nearly(got, test.want) {
+				t.Fatalf("%s = %v, want %v", test.output, got, test.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateDiscard(t *testing.T) {
+	transparent := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	_, err := shaders.Evaluate(new(Ring), map[string]any{"UV": Vector2.XY{X: 0.8, Y: 0.5}, "mask": transparent})
+	if !errors.Is(err, shaders.ErrDiscarded) {
+		t.Fatalf("expected the pixel to be discarded, got %v", err)
+	}
+}
+
+// opaque is a white mask, that never discards.
+var opaque = func() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, color.White)
+	return img
+}()
+
+// nearly reports whether the outputs are equal, to within float32 rounding.
+func nearly(got, want any) bool {
+	near := func(a, b float32) bool { return a-b < 1e-6 && b-a < 1e-6 }
+	switch want := want.(type) {
+	case float32:
+		got, ok := got.(float32)
+		return ok && near(got, want)
+	case Color.RGB:
+		got, ok := got.(Color.RGB)
+		return ok && near(got.R, want.R) && near(got.G, want.G) && near(got.B, want.B)
+	case Color.RGBA:
+		got, ok := got.(Color.RGBA)
+		return ok && near(got.R, want.R) && near(got.G, want.G) && near(got.B, want.B) && near(got.A, want.A)
+	}
+	return false
+}
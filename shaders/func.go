@@ -0,0 +1,98 @@
+package shaders
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"graphics.gd/shaders/internal/gpu"
+)
+
+// Func converts a Go function into a GLSL function, such that it is emitted once into each shader
+// that calls it and then called by name, instead of being inlined into the shader at each call
+// site. The parameters and result of fn must be GPU types and fn should only depend on its
+// parameters, uniforms and globals. GLSL functions cannot be recursive, so fn must not call
+// itself (directly or indirectly).
+//
+//	var noise = shaders.Func(func(uv vec2.XY) float.X {
+//		return float.Fract(float.Mul(float.Sin(vec2.Dot(uv, vec2.New(12.9898, 78.233))), 43758.5453))
+//	})
+func Func[F any](fn F) F {
+	rtype := reflect.TypeFor[F]()
+	if rtype.Kind() != reflect.Func || rtype.NumOut() != 1 || rtype.IsVariadic() {
+		panic(fmt.Sprintf("shaders.Func: %v must be a function with a single result", rtype))
+	}
+	evaluator := reflect.TypeFor[gpu.Evaluator]()
+	for i := range rtype.NumIn() {
+		if !rtype.In(i).Implements(evaluator) {
+			panic(fmt.Sprintf("shaders.Func: parameter %d of %v is not a GPU type", i, rtype))
+		}
+	}
+	if !rtype.Out(0).Implements(evaluator) {
+		panic(fmt.Sprintf("shaders.Func: result of %v is not a GPU type", rtype))
+	}
+	value := reflect.ValueOf(fn)
+	name := functionName(runtime.FuncForPC(value.Pointer()).Name())
+	key := new(byte) // identifies this function across calls.
+	record := func(id gpu.Identifier) gpu.Function {
+		def := gpu.Function{Name: id, Result: rtype.Out(0)}
+		params := make([]reflect.Value, rtype.NumIn())
+		for i := range params {
+			param := gpu.Identifier(fmt.Sprintf("_a%d", i))
+			params[i] = reflect.New(rtype.In(i)).Elem()
+			identify(params[i], param)
+			def.Params = append(def.Params, gpu.Declare{Name: param, Type: rtype.In(i)})
+		}
+		def.Body = gpu.Record(false, func() {
+			def.Return = value.Call(params)[0].Interface().(gpu.Evaluator)
+		})
+		return def
+	}
+	return reflect.MakeFunc(rtype, func(args []reflect.Value) []reflect.Value {
+		call := gpu.FunctionCall{Name: string(gpu.Define(key, name, record))}
+		for _, arg := range args {
+			call.Args = append(call.Args, arg.Interface().(gpu.Evaluator))
+		}
+		result := reflect.New(rtype.Out(0)).Elem()
+		gpu.Set(result.Addr().Interface().(gpu.Pointer), gpu.New(call))
+		return []reflect.Value{result}
+	}).Interface().(F)
+}
+
+// functionName converts a fully qualified Go function name into a GLSL identifier. Closures are
+// named after the function they are declared in (without the funcN suffix that Go gives them) and
+// closures declared at package level are named fn, any duplicate names are numbered by the caller.
+func functionName(qualified string) string {
+	name := qualified[strings.LastIndex(qualified, "/")+1:]
+	if _, after, ok := strings.Cut(name, "."); ok {
+		name = after
+	}
+	var outer []string
+	for _, segment := range strings.Split(name, ".") {
+		if segment == "" || segment == "glob" || strings.TrimLeft(strings.TrimPrefix(segment, "func"), "0123456789") == "" {
+			continue
+		}
+		if before, _, ok := strings.Cut(segment, "["); ok {
+			segment = before // type parameters.
+		}
+		outer = append(outer, segment)
+	}
+	if len(outer) == 0 || len(outer) == 1 && outer[0] == "init" {
+		return "fn"
+	}
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.Join(outer, "_"))
+	for strings.Contains(name, "__") {
+		name = strings.ReplaceAll(name, "__", "_")
+	}
+	name = strings.Trim(name, "_")
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "fn_" + name
+	}
+	return name
+}
@@ -0,0 +1,45 @@
+package shaders
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	gd "graphics.gd/internal"
+	"graphics.gd/internal/gdclass"
+	"graphics.gd/variant/String"
+)
+
+func init() {
+	gd.Generators["shaders"] = generate
+}
+
+// generate writes the source of each registered shader class into the directory, as a .gdshader
+// file named after the Go type, see 'gd generate shaders'. Shader types with the same name (from
+// different packages) are reported as an error, rather than overwriting each other's files.
+func generate(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	written := make(map[string]reflect.Type)
+	for _, class := range gdclass.Declared {
+		if class.Kind() != reflect.Struct || !reflect.PointerTo(class).Implements(reflect.TypeFor[Any]()) {
+			continue
+		}
+		code, err := Source(reflect.New(class).Interface().(Any))
+		if err != nil {
+			return err
+		}
+		name := String.ToSnakeCase(class.Name()) + ".gdshader"
+		if other, ok := written[name]; ok {
+			return fmt.Errorf("%s.%s and %s.%s would both be written to %s, rename one of them",
+				other.PkgPath(), other.Name(), class.PkgPath(), class.Name(), name)
+		}
+		written[name] = class
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(code), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package shaders_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	gd "graphics.gd/internal"
+	"graphics.gd/internal/gdclass"
+	"graphics.gd/shaders/pipeline/CanvasItem"
+)
+
+func TestGenerate(t *testing.T) {
+	declared := gdclass.Declared
+	defer func() { gdclass.Declared = declared }()
+	gdclass.Declared = []reflect.Type{reflect.TypeFor[Solid](), reflect.TypeFor[Wave]()}
+
+	dir := t.TempDir()
+	if err := gd.Generators["shaders"](dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"solid.gdshader", "wave.gdshader"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	type Solid struct {
+		CanvasItem.Shader[Solid]
+	}
+	gdclass.Declared = append(gdclass.Declared, reflect.TypeFor[Solid]())
+	err := gd.Generators["shaders"](t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "solid.gdshader") {
+		t.Fatalf("expected an error for the two Solid shaders, got %v", err)
+	}
+}
@@ -0,0 +1,379 @@
+package cpu
+
+import (
+	"image/color"
+	"math"
+
+	"graphics.gd/shaders/internal/gpu"
+)
+
+// unary built-in functions, applied to each float component.
+var unary = map[string]func(float64) float64{
+	"acos":        math.Acos,
+	"acosh":       math.Acosh,
+	"asin":        math.Asin,
+	"asinh":       math.Asinh,
+	"atanh":       math.Atanh,
+	"ceil":        math.Ceil,
+	"cos":         math.Cos,
+	"cosh":        math.Cosh,
+	"exp":         math.Exp,
+	"exp2":        math.Exp2,
+	"floor":       math.Floor,
+	"log":         math.Log,
+	"log2":        math.Log2,
+	"round":       math.Round,
+	"roundEven":   math.RoundToEven,
+	"roundeven":   math.RoundToEven,
+	"sin":         math.Sin,
+	"sinh":        math.Sinh,
+	"sqrt":        math.Sqrt,
+	"tan":         math.Tan,
+	"tanh":        math.Tanh,
+	"trunc":       math.Trunc,
+	"inversesqrt": func(x float64) float64 { return 1 / math.Sqrt(x) },
+	"inverseSqrt": func(x float64) float64 { return 1 / math.Sqrt(x) },
+	"fract":       func(x float64) float64 { return x - math.Floor(x) },
+	"radians":     func(x float64) float64 { return x * math.Pi / 180 },
+	"degrees":     func(x float64) float64 { return x * 180 / math.Pi },
+}
+
+// comparisons that return a bool vector.
+var comparisons = map[string]func(x, y float64) bool{
+	"equal":            func(x, y float64) bool { return x == y },
+	"notEqual":         func(x, y float64) bool { return x != y },
+	"lessThan":         func(x, y float64) bool { return x < y },
+	"lessThanEqual":    func(x, y float64) bool { return x <= y },
+	"greaterThan":      func(x, y float64) bool { return x > y },
+	"greaterThanEqual": func(x, y float64) bool { return x >= y },
+}
+
+// call evaluates the built-in or user-defined function.
+func (m *Machine) call(name string, exprs []gpu.Evaluator) Value {
+	if fn, ok := m.Functions[gpu.Identifier(name)]; ok {
+		return m.define(fn, exprs)
+	}
+	if name == "modf" {
+		x := m.eval(exprs[0])
+		out, ok := gpu.Evaluate(exprs[1]).(gpu.Output)
+		if !ok {
+			fail("modf requires an output parameter")
+		}
+		m.outputs[out.Index] = each(Float, math.Trunc, x)
+		return each(Float, func(x float64) float64 { return x - math.Trunc(x) }, x)
+	}
+	args := make([]Value, len(exprs))
+	for i, expr := range exprs {
+		args[i] = m.eval(expr)
+	}
+	if fn, ok := unary[name]; ok && len(args) == 1 {
+		return each(Float, fn, args[0])
+	}
+	if fn, ok := comparisons[name]; ok {
+		return zip(Bool, func(x ...float64) float64 { return boolean(fn(x[0], x[1])) }, args...)
+	}
+	if kind, rows, cols, ok := parse(name); ok && kind != Sampler {
+		return construct(kind, rows, cols, args)
+	}
+	switch name {
+	case "abs":
+		return each(args[0].Kind, math.Abs, args[0])
+	case "sign":
+		return each(args[0].Kind, sign, args[0])
+	case "atan":
+		if len(args) == 2 {
+			return zip(Float, func(x ...float64) float64 { return math.Atan2(x[0], x[1]) }, args...)
+		}
+		return each(Float, math.Atan, args[0])
+	case "pow":
+		return zip(Float, func(x ...float64) float64 { return math.Pow(x[0], x[1]) }, args...)
+	case "mod":
+		return zip(Float, func(x ...float64) float64 { return x[0] - x[1]*math.Floor(x[0]/x[1]) }, args...)
+	case "min":
+		return zip(args[0].Kind, func(x ...float64) float64 { return math.Min(x[0], x[1]) }, args...)
+	case "max":
+		return zip(args[0].Kind, func(x ...float64) float64 { return math.Max(x[0], x[1]) }, args...)
+	case "clamp":
+		return zip(args[0].Kind, func(x ...float64) float64 { return math.Min(math.Max(x[0], x[1]), x[2]) }, args...)
+	case "step":
+		return zip(Float, func(x ...float64) float64 { return boolean(x[1] >= x[0]) }, args...)
+	case "mix":
+		if args[2].Kind == Bool {
+			return zip(args[0].Kind, func(x ...float64) float64 {
+				if x[2] != 0 {
+					return x[1]
+				}
+				return x[0]
+			}, args...)
+		}
+		return zip(Float, func(x ...float64) float64 { return x[0]*(1-x[2]) + x[1]*x[2] }, args...)
+	case "smoothstep":
+		return zip(Float, func(x ...float64) float64 {
+			t := math.Min(math.Max((x[2]-x[0])/(x[1]-x[0]), 0), 1)
+			return t * t * (3 - 2*t)
+		}, args...)
+	case "isnan":
+		return each(Bool, func(x float64) float64 { return boolean(math.IsNaN(x)) }, args[0])
+	case "isinf":
+		return each(Bool, func(x float64) float64 { return boolean(math.IsInf(x, 0)) }, args[0])
+	case "not":
+		return each(Bool, func(x float64) float64 { return boolean(x == 0) }, args[0])
+	case "any", "all":
+		result := name == "all"
+		for _, x := range args[0].C {
+			if name == "any" && x != 0 {
+				result = true
+			}
+			if name == "all" && x == 0 {
+				result = false
+			}
+		}
+		return Scalar(Bool, boolean(result))
+	case "floatBitsToInt":
+		return each(Int, func(x float64) float64 { return float64(int32(math.Float32bits(float32(x)))) }, args[0])
+	case "floatBitsToUint":
+		return each(Uint, func(x float64) float64 { return float64(math.Float32bits(float32(x))) }, args[0])
+	case "intBitsToFloat", "uintBitsToFloat":
+		return each(Float, func(x float64) float64 { return float64(math.Float32frombits(uint32(int64(x)))) }, args[0])
+	case "dot":
+		return Scalar(Float, dot(args[0], args[1]))
+	case "length":
+		return Scalar(Float, math.Sqrt(dot(args[0], args[0])))
+	case "distance":
+		d := zip(Float, func(x ...float64) float64 { return x[0] - x[1] }, args...)
+		return Scalar(Float, math.Sqrt(dot(d, d)))
+	case "normalize":
+		length := math.Sqrt(dot(args[0], args[0]))
+		return each(Float, func(x float64) float64 { return x / length }, args[0])
+	case "cross":
+		a, b := args[0].C, args[1].C
+		return Vector(Float, a[1]*b[2]-b[1]*a[2], a[2]*b[0]-b[2]*a[0], a[0]*b[1]-b[0]*a[1])
+	case "reflect":
+		d := dot(args[1], args[0])
+		return zip(Float, func(x ...float64) float64 { return x[0] - 2*d*x[1] }, args[0], args[1])
+	case "refract":
+		eta := m.scalar(args[2])
+		d := dot(args[1], args[0])
+		k := 1 - eta*eta*(1-d*d)
+		if k < 0 {
+			return each(Float, func(float64) float64 { return 0 }, args[0])
+		}
+		return zip(Float, func(x ...float64) float64 { return eta*x[0] - (eta*d+math.Sqrt(k))*x[1] }, args[0], args[1])
+	case "faceforward":
+		if dot(args[2], args[1]) < 0 {
+			return args[0]
+		}
+		return each(Float, func(x float64) float64 { return -x }, args[0])
+	case "dFdx", "dFdy", "fwidth":
+		return each(Float, func(float64) float64 { return 0 }, args[0]) // a single pixel has no neighbours.
+	case "matrixCompMult":
+		return zip(Float, func(x ...float64) float64 { return x[0] * x[1] }, args...)
+	case "outerProduct":
+		c, r := args[0], args[1]
+		out := Value{Kind: Float, Rows: len(c.C), Cols: len(r.C)}
+		for _, y := range r.C {
+			for _, x := range c.C {
+				out.C = append(out.C, round(Float, x*y))
+			}
+		}
+		return out
+	case "transpose":
+		return transpose(args[0])
+	case "determinant":
+		return Scalar(Float, determinant(args[0]))
+	case "inverse":
+		return inverse(args[0])
+	case "packUnorm2x16":
+		return Scalar(Uint, pack2x16(args[0], func(x float64) uint16 { return uint16(math.Round(math.Min(math.Max(x, 0), 1) * 65535)) }))
+	case "packSnorm2x16":
+		return Scalar(Uint, pack2x16(args[0], func(x float64) uint16 { return uint16(int16(math.Round(math.Min(math.Max(x, -1), 1) * 32767))) }))
+	case "unpackUnorm2x16":
+		return unpack2x16(args[0], func(x uint16) float64 { return float64(x) / 65535 })
+	case "unpackSnorm2x16":
+		return unpack2x16(args[0], func(x uint16) float64 { return math.Min(math.Max(float64(int16(x))/32767, -1), 1) })
+	case "texture", "textureLod":
+		return sample(args[0], args[1])
+	case "texelFetch":
+		return texel(args[0], int(args[1].C[0]), int(args[1].C[1]))
+	case "textureSize":
+		if args[0].Image == nil {
+			return Vector(Int, 0, 0)
+		}
+		size := args[0].Image.Bounds().Size()
+		return Vector(Int, float64(size.X), float64(size.Y))
+	}
+	fail("%s is not supported on the CPU", name)
+	return Value{}
+}
+
+// define calls the user-defined function, with its own set of locals.
+func (m *Machine) define(fn gpu.Function, exprs []gpu.Evaluator) Value {
+	locals := make(map[string]Value)
+	for i, param := range fn.Params {
+		locals[string(param.Name)] = m.eval(exprs[i])
+	}
+	parent := m.locals
+	m.locals = locals
+	defer func() { m.locals = parent }()
+	m.exec(fn.Body)
+	return m.eval(fn.Return)
+}
+
+// construct a value of the given type from the arguments, as with a GLSL constructor.
+func construct(kind Kind, rows, cols int, args []Value) Value {
+	out := Value{Kind: kind, Rows: rows, Cols: cols, C: make([]float64, rows*cols)}
+	convert := func(x float64) float64 {
+		if kind == Bool {
+			return boolean(x != 0)
+		}
+		return round(kind, x)
+	}
+	switch {
+	case len(args) == 1 && len(args[0].C) == 1 && cols > 1:
+		for i := range cols {
+			out.C[i*rows+i] = convert(args[0].C[0])
+		}
+	case len(args) == 1 && len(args[0].C) == 1:
+		for i := range out.C {
+			out.C[i] = convert(args[0].C[0])
+		}
+	case len(args) == 1 && args[0].Cols > 1 && cols > 1:
+		from := args[0]
+		for c := range cols {
+			for r := range rows {
+				switch {
+				case c < from.Cols && r < from.Rows:
+					out.C[c*rows+r] = from.C[c*from.Rows+r]
+				case c == r:
+					out.C[c*rows+r] = 1
+				}
+			}
+		}
+	default:
+		var components []float64
+		for _, arg := range args {
+			if arg.Kind == Sampler {
+				fail("cannot construct a value from a sampler")
+			}
+			components = append(components, arg.C...)
+		}
+		if len(components) < len(out.C) {
+			fail("not enough components to construct a %s", out.TypeName())
+		}
+		for i := range out.C {
+			out.C[i] = convert(components[i])
+		}
+	}
+	return out
+}
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+func dot(a, b Value) float64 {
+	if len(a.C) != len(b.C) {
+		fail("cannot dot a %s with a %s", a.TypeName(), b.TypeName())
+	}
+	var sum float64
+	for i := range a.C {
+		sum = round(Float, sum+round(Float, a.C[i]*b.C[i]))
+	}
+	return sum
+}
+
+func transpose(a Value) Value {
+	out := Value{Kind: Float, Rows: a.Cols, Cols: a.Rows, C: make([]float64, len(a.C))}
+	for c := range a.Cols {
+		for r := range a.Rows {
+			out.C[r*a.Cols+c] = a.C[c*a.Rows+r]
+		}
+	}
+	return out
+}
+
+// minor returns the matrix without the given column and row.
+func minor(a Value, col, row int) Value {
+	out := Value{Kind: Float, Rows: a.Rows - 1, Cols: a.Cols - 1}
+	for c := range a.Cols {
+		for r := range a.Rows {
+			if c != col && r != row {
+				out.C = append(out.C, a.C[c*a.Rows+r])
+			}
+		}
+	}
+	return out
+}
+
+func determinant(a Value) float64 {
+	if a.Rows != a.Cols {
+		fail("cannot take the determinant of a %s", a.TypeName())
+	}
+	if a.Rows == 1 {
+		return a.C[0]
+	}
+	var det float64
+	for c := range a.Cols {
+		det += a.C[c*a.Rows] * determinant(minor(a, c, 0)) * sign(0.5-float64(c%2))
+	}
+	return det
+}
+
+func inverse(a Value) Value {
+	det := determinant(a)
+	out := Value{Kind: Float, Rows: a.Rows, Cols: a.Cols, C: make([]float64, len(a.C))}
+	for c := range a.Cols {
+		for r := range a.Rows {
+			cofactor := determinant(minor(a, r, c)) * sign(0.5-float64((r+c)%2))
+			out.C[c*a.Rows+r] = round(Float, cofactor/det)
+		}
+	}
+	return out
+}
+
+func pack2x16(v Value, fn func(float64) uint16) float64 {
+	return float64(uint32(fn(v.C[0])) | uint32(fn(v.C[1]))<<16)
+}
+
+func unpack2x16(v Value, fn func(uint16) float64) Value {
+	x := uint32(v.C[0])
+	return Vector(Float, fn(uint16(x)), fn(uint16(x>>16)))
+}
+
+// sample the texture at the given coordinates, with nearest filtering and repeat enabled.
+func sample(sampler, uv Value) Value {
+	if sampler.Kind != Sampler {
+		fail("expected a sampler, not a %s", sampler.TypeName())
+	}
+	if sampler.Image == nil {
+		return Vector(Float, 0, 0, 0, 0)
+	}
+	size := sampler.Image.Bounds().Size()
+	wrap := func(x float64, n int) int {
+		return min(int(math.Floor((x-math.Floor(x))*float64(n))), n-1)
+	}
+	return texel(sampler, wrap(uv.C[0], size.X), wrap(uv.C[1], size.Y))
+}
+
+// texel returns the pixel of the texture, at the given integer coordinates.
+func texel(sampler Value, x, y int) Value {
+	if sampler.Kind != Sampler {
+		fail("expected a sampler, not a %s", sampler.TypeName())
+	}
+	if sampler.Image == nil {
+		return Vector(Float, 0, 0, 0, 0)
+	}
+	bounds := sampler.Image.Bounds()
+	x, y = bounds.Min.X+x, bounds.Min.Y+y
+	if x < bounds.Min.X || y < bounds.Min.Y || x >= bounds.Max.X || y >= bounds.Max.Y {
+		return Vector(Float, 0, 0, 0, 0)
+	}
+	c := color.NRGBA64Model.Convert(sampler.Image.At(x, y)).(color.NRGBA64)
+	return Vector(Float, float64(c.R)/0xffff, float64(c.G)/0xffff, float64(c.B)/0xffff, float64(c.A)/0xffff)
+}
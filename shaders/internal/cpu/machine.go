@@ -0,0 +1,456 @@
+package cpu
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"graphics.gd/shaders/internal/gpu"
+)
+
+// Machine evaluates the expressions and runs the statements of a shader for a single pixel.
+type Machine struct {
+	Values    map[string]Value                // built-ins, uniforms and stage outputs, by GLSL name.
+	Functions map[gpu.Identifier]gpu.Function // user-defined functions, by name.
+	Discarded bool                            // set once a Discard statement has run.
+
+	locals  map[string]Value
+	outputs map[*int]Value
+}
+
+// New returns a new machine, without any values.
+func New() *Machine {
+	return &Machine{
+		Values:    make(map[string]Value),
+		Functions: make(map[gpu.Identifier]gpu.Function),
+		locals:    make(map[string]Value),
+		outputs:   make(map[*int]Value),
+	}
+}
+
+var constants = map[string]Value{
+	"PI":  Scalar(Float, math.Pi),
+	"TAU": Scalar(Float, 2*math.Pi),
+	"E":   Scalar(Float, math.E),
+}
+
+// failure is raised (as a panic) by the machine and returned as an error by [Machine.Run] and
+// [Machine.Eval].
+type failure struct{ err error }
+
+func fail(format string, args ...any) {
+	panic(failure{fmt.Errorf(format, args...)})
+}
+
+func catch(err *error) {
+	if r := recover(); r != nil {
+		if f, ok := r.(failure); ok {
+			*err = f.err
+			return
+		}
+		panic(r)
+	}
+}
+
+// Run the statements, stopping early if the pixel is discarded.
+func (m *Machine) Run(statements []gpu.Statement) (err error) {
+	defer catch(&err)
+	m.exec(statements)
+	return nil
+}
+
+// Eval returns the value of the expression.
+func (m *Machine) Eval(expr gpu.Evaluator) (value Value, err error) {
+	defer catch(&err)
+	return m.eval(expr), nil
+}
+
+// flow is how control leaves a block of statements.
+type flow int
+
+const (
+	next flow = iota
+	breaking
+	continuing
+	discarding
+)
+
+func (m *Machine) exec(statements []gpu.Statement) flow {
+	for _, stmt := range statements {
+		switch stmt := stmt.(type) {
+		case gpu.Declare:
+			m.locals[string(stmt.Name)] = m.eval(stmt.Value)
+		case gpu.Assign:
+			if _, ok := m.locals[string(stmt.Name)]; !ok {
+				fail("assignment to undeclared variable %s", stmt.Name)
+			}
+			m.locals[string(stmt.Name)] = m.eval(stmt.Value)
+		case gpu.Eval:
+			m.eval(stmt.Value)
+		case gpu.Store:
+			fail("stores into buffers and images are not supported on the CPU")
+		case gpu.If:
+			var result flow
+			if m.truth(m.eval(stmt.Cond)) {
+				result = m.exec(stmt.Then)
+			} else {
+				result = m.exec(stmt.Else)
+			}
+			if result != next {
+				return result
+			}
+		case gpu.Loop:
+			for i := 0; float64(i) < m.scalar(m.eval(stmt.Count)); i++ {
+				m.locals[string(stmt.Index)] = Scalar(Int, float64(i))
+				result := m.exec(stmt.Body)
+				if result == breaking {
+					break
+				}
+				if result == discarding {
+					return result
+				}
+			}
+		case gpu.Break:
+			return breaking
+		case gpu.Continue:
+			return continuing
+		case gpu.Discard:
+			m.Discarded = true
+			return discarding
+		default:
+			fail("unsupported statement %T", stmt)
+		}
+	}
+	return next
+}
+
+func (m *Machine) eval(expr gpu.Evaluator) Value {
+	if e := gpu.Evaluate(expr); e != nil {
+		expr = e
+	}
+	switch e := expr.(type) {
+	case gpu.Identifier:
+		return m.lookup(string(e))
+	case gpu.Operation:
+		return m.operation(e)
+	case gpu.Ternary:
+		if m.truth(m.eval(e.If)) {
+			return m.eval(e.A)
+		}
+		return m.eval(e.B)
+	case gpu.FunctionCall:
+		return m.call(e.Name, e.Args)
+	case gpu.Output:
+		if v, ok := m.outputs[e.Index]; ok {
+			return v
+		}
+		v, err := Zero(e.Type)
+		if err != nil {
+			fail("%v", err)
+		}
+		return v
+	case gpu.Index:
+		fail("arrays are not supported on the CPU")
+	}
+	return m.literal(reflect.ValueOf(expr))
+}
+
+// literal converts a constant GPU value, whose components may themselves be expressions.
+func (m *Machine) literal(value reflect.Value) Value {
+	kind, rows, cols, ok := parse(gpu.TypeName(value.Type()))
+	if !ok || kind == Sampler {
+		fail("unsupported constant of type %v", value.Type())
+	}
+	if rows*cols == 1 {
+		x := value.FieldByName("X")
+		switch x.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return Scalar(kind, x.Float())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return Scalar(kind, float64(x.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return Scalar(kind, float64(x.Uint()))
+		case reflect.Bool:
+			return Scalar(kind, boolean(x.Bool()))
+		}
+		fail("unsupported constant of type %v", value.Type())
+	}
+	out := Value{Kind: kind, Rows: rows, Cols: cols}
+	m.components(value, &out.C)
+	if len(out.C) != rows*cols {
+		fail("constant %v has %d components, expected %d", value.Type(), len(out.C), rows*cols)
+	}
+	for i := range out.C {
+		out.C[i] = round(kind, out.C[i])
+	}
+	return out
+}
+
+// components appends the values of each component of the constant.
+func (m *Machine) components(value reflect.Value, into *[]float64) {
+	switch value.Kind() {
+	case reflect.Array:
+		for i := range value.Len() {
+			m.component(value.Index(i), into)
+		}
+	case reflect.Struct:
+		for i := range value.NumField() {
+			if !value.Type().Field(i).Anonymous {
+				m.component(value.Field(i), into)
+			}
+		}
+	}
+}
+
+func (m *Machine) component(value reflect.Value, into *[]float64) {
+	if expr, ok := value.Interface().(gpu.Evaluator); ok {
+		*into = append(*into, m.eval(expr).C...)
+		return
+	}
+	m.components(value, into)
+}
+
+func (m *Machine) lookup(name string) Value {
+	if v, ok := m.locals[name]; ok {
+		return v
+	}
+	if v, ok := m.Values[name]; ok {
+		return v
+	}
+	if base, components, ok := strings.Cut(name, "."); ok {
+		v, err := m.lookup(base).swizzle(components)
+		if err != nil {
+			fail("%s: %v", name, err)
+		}
+		return v
+	}
+	if v, ok := constants[name]; ok {
+		return v
+	}
+	fail("no value was provided for %s", name)
+	return Value{}
+}
+
+// truth returns the value of a bool scalar.
+func (m *Machine) truth(v Value) bool {
+	if v.Kind != Bool || len(v.C) != 1 {
+		fail("expected a bool, not a %s", v.TypeName())
+	}
+	return v.C[0] != 0
+}
+
+// scalar returns the value of a numeric scalar.
+func (m *Machine) scalar(v Value) float64 {
+	if v.Kind == Sampler || len(v.C) != 1 {
+		fail("expected a scalar, not a %s", v.TypeName())
+	}
+	return v.C[0]
+}
+
+func (m *Machine) operation(op gpu.Operation) Value {
+	if op.A == nil {
+		b := m.eval(op.B)
+		switch op.Op {
+		case "-":
+			return each(b.Kind, func(x float64) float64 { return -x }, b)
+		case "!":
+			return Scalar(Bool, boolean(!m.truth(b)))
+		}
+		fail("unsupported unary operator %s", op.Op)
+	}
+	switch op.Op {
+	case "&&":
+		return Scalar(Bool, boolean(m.truth(m.eval(op.A)) && m.truth(m.eval(op.B))))
+	case "||":
+		return Scalar(Bool, boolean(m.truth(m.eval(op.A)) || m.truth(m.eval(op.B))))
+	}
+	a, b := m.eval(op.A), m.eval(op.B)
+	switch op.Op {
+	case "==":
+		return Scalar(Bool, boolean(equal(a, b)))
+	case "!=":
+		return Scalar(Bool, boolean(!equal(a, b)))
+	case "<":
+		return Scalar(Bool, boolean(m.scalar(a) < m.scalar(b)))
+	case "<=":
+		return Scalar(Bool, boolean(m.scalar(a) <= m.scalar(b)))
+	case ">":
+		return Scalar(Bool, boolean(m.scalar(a) > m.scalar(b)))
+	case ">=":
+		return Scalar(Bool, boolean(m.scalar(a) >= m.scalar(b)))
+	case "*":
+		if a.Cols > 1 && len(b.C) > 1 || b.Cols > 1 && len(a.C) > 1 {
+			return multiply(a, b)
+		}
+		fallthrough
+	case "+", "-", "/", "%":
+		kind := a.Kind
+		return zip(kind, func(x ...float64) float64 { return arithmetic(kind, op.Op, x[0], x[1]) }, a, b)
+	}
+	fail("unsupported operator %s", op.Op)
+	return Value{}
+}
+
+// arithmetic applies the operator to x and y, with the overflow and division semantics of the
+// kind.
+func arithmetic(kind Kind, op string, x, y float64) float64 {
+	switch kind {
+	case Int:
+		a, b := int32(x), int32(y)
+		switch op {
+		case "+":
+			return float64(a + b)
+		case "-":
+			return float64(a - b)
+		case "*":
+			return float64(a * b)
+		case "/":
+			if b == 0 {
+				return 0
+			}
+			return float64(a / b)
+		case "%":
+			if b == 0 {
+				return 0
+			}
+			return float64(a % b)
+		}
+	case Uint:
+		a, b := uint32(x), uint32(y)
+		switch op {
+		case "+":
+			return float64(a + b)
+		case "-":
+			return float64(a - b)
+		case "*":
+			return float64(a * b)
+		case "/":
+			if b == 0 {
+				return 0
+			}
+			return float64(a / b)
+		case "%":
+			if b == 0 {
+				return 0
+			}
+			return float64(a % b)
+		}
+	case Float:
+		switch op {
+		case "+":
+			return x + y
+		case "-":
+			return x - y
+		case "*":
+			return x * y
+		case "/":
+			return x / y
+		case "%":
+			return x - y*math.Floor(x/y)
+		}
+	}
+	fail("unsupported operator %s", op)
+	return 0
+}
+
+func equal(a, b Value) bool {
+	if a.Kind != b.Kind || len(a.C) != len(b.C) {
+		return false
+	}
+	for i := range a.C {
+		if a.C[i] != b.C[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// multiply performs the linear algebraic product of matrices and vectors.
+func multiply(a, b Value) Value {
+	switch {
+	case a.Cols > 1 && b.Cols > 1:
+		if a.Cols != b.Rows {
+			fail("cannot multiply a %s by a %s", a.TypeName(), b.TypeName())
+		}
+		out := Value{Kind: Float, Rows: a.Rows, Cols: b.Cols, C: make([]float64, a.Rows*b.Cols)}
+		for c := range b.Cols {
+			for r := range a.Rows {
+				var sum float64
+				for k := range a.Cols {
+					sum = round(Float, sum+round(Float, a.C[k*a.Rows+r]*b.C[c*b.Rows+k]))
+				}
+				out.C[c*a.Rows+r] = sum
+			}
+		}
+		return out
+	case a.Cols > 1:
+		if a.Cols != len(b.C) {
+			fail("cannot multiply a %s by a %s", a.TypeName(), b.TypeName())
+		}
+		out := Value{Kind: Float, Rows: a.Rows, Cols: 1, C: make([]float64, a.Rows)}
+		for r := range a.Rows {
+			var sum float64
+			for k := range a.Cols {
+				sum = round(Float, sum+round(Float, a.C[k*a.Rows+r]*b.C[k]))
+			}
+			out.C[r] = sum
+		}
+		return out
+	default:
+		if b.Rows != len(a.C) {
+			fail("cannot multiply a %s by a %s", a.TypeName(), b.TypeName())
+		}
+		out := Value{Kind: Float, Rows: b.Cols, Cols: 1, C: make([]float64, b.Cols)}
+		for c := range b.Cols {
+			var sum float64
+			for k := range b.Rows {
+				sum = round(Float, sum+round(Float, a.C[k]*b.C[c*b.Rows+k]))
+			}
+			out.C[c] = sum
+		}
+		return out
+	}
+}
+
+// zip applies fn to each component of the values, scalars are broadcast to the shape of the
+// largest value and the results are rounded to the given kind.
+func zip(kind Kind, fn func(x ...float64) float64, values ...Value) Value {
+	shape := values[0]
+	for _, v := range values {
+		if v.Kind == Sampler {
+			fail("cannot use a sampler as a value")
+		}
+		if len(v.C) > len(shape.C) {
+			shape = v
+		}
+	}
+	out := Value{Kind: kind, Rows: shape.Rows, Cols: shape.Cols, C: make([]float64, len(shape.C))}
+	args := make([]float64, len(values))
+	for i := range out.C {
+		for j, v := range values {
+			switch len(v.C) {
+			case 1:
+				args[j] = v.C[0]
+			case len(shape.C):
+				args[j] = v.C[i]
+			default:
+				fail("mismatched %s and %s", v.TypeName(), shape.TypeName())
+			}
+		}
+		out.C[i] = round(kind, fn(args...))
+	}
+	return out
+}
+
+// each applies fn to each component of v, rounding the results to the given kind.
+func each(kind Kind, fn func(x float64) float64, v Value) Value {
+	return zip(kind, func(x ...float64) float64 { return fn(x[0]) }, v)
+}
+
+func boolean(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
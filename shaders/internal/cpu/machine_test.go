@@ -0,0 +1,250 @@
+package cpu
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"testing"
+
+	"graphics.gd/shaders/internal/gpu"
+)
+
+func f(x ...float64) Value { return Vector(Float, x...) }
+func i(x ...float64) Value { return Vector(Int, x...) }
+func u(x ...float64) Value { return Vector(Uint, x...) }
+func b(x ...float64) Value { return Vector(Bool, x...) }
+
+// mat returns a square matrix with the given components, in column-major order.
+func mat(n int, c ...float64) Value {
+	v := Value{Kind: Float, Rows: n, Cols: n}
+	for _, x := range c {
+		v.C = append(v.C, round(Float, x))
+	}
+	return v
+}
+
+// eval evaluates the expression, with the given arguments available as a0, a1 and so on.
+func eval(t *testing.T, build func(args ...gpu.Evaluator) gpu.Evaluator, args ...Value) Value {
+	t.Helper()
+	m := New()
+	var ids []gpu.Evaluator
+	for n, arg := range args {
+		name := fmt.Sprintf("a%d", n)
+		m.Values[name] = arg
+		ids = append(ids, gpu.Identifier(name))
+	}
+	v, err := m.Eval(build(ids...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func call(name string) func(args ...gpu.Evaluator) gpu.Evaluator {
+	return func(args ...gpu.Evaluator) gpu.Evaluator { return gpu.FunctionCall{Name: name, Args: args} }
+}
+
+func op(operator string) func(args ...gpu.Evaluator) gpu.Evaluator {
+	return func(args ...gpu.Evaluator) gpu.Evaluator { return gpu.Operation{A: args[0], Op: operator, B: args[1]} }
+}
+
+// same reports whether the values have the same kind, shape and (nearly) the same components.
+func same(got, want Value) bool {
+	if got.Kind != want.Kind || got.Rows != want.Rows || got.Cols != want.Cols || len(got.C) != len(want.C) {
+		return false
+	}
+	for n := range got.C {
+		x, y := got.C[n], want.C[n]
+		if math.IsNaN(x) && math.IsNaN(y) || x == y {
+			continue
+		}
+		if math.Abs(x-y) > 1e-6*math.Max(1, math.Abs(y)) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBuiltins(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		args []Value
+		want Value
+	}{
+		{"sin", []Value{f(1)}, f(math.Sin(1))},
+		{"cos", []Value{f(1)}, f(math.Cos(1))},
+		{"tan", []Value{f(1)}, f(math.Tan(1))},
+		{"asin", []Value{f(0.5)}, f(math.Asin(0.5))},
+		{"acos", []Value{f(0.5)}, f(math.Acos(0.5))},
+		{"atan", []Value{f(1)}, f(math.Pi / 4)},
+		{"atan", []Value{f(1), f(-1)}, f(3 * math.Pi / 4)},
+		{"sinh", []Value{f(1)}, f(math.Sinh(1))},
+		{"cosh", []Value{f(1)}, f(math.Cosh(1))},
+		{"tanh", []Value{f(1)}, f(math.Tanh(1))},
+		{"asinh", []Value{f(1)}, f(math.Asinh(1))},
+		{"acosh", []Value{f(2)}, f(math.Acosh(2))},
+		{"atanh", []Value{f(0.5)}, f(math.Atanh(0.5))},
+		{"exp", []Value{f(1)}, f(math.E)},
+		{"exp2", []Value{f(3)}, f(8)},
+		{"log", []Value{f(math.E)}, f(1)},
+		{"log2", []Value{f(8)}, f(3)},
+		{"sqrt", []Value{f(4, 9)}, f(2, 3)},
+		{"inversesqrt", []Value{f(4)}, f(0.5)},
+		{"ceil", []Value{f(1.2, -1.2)}, f(2, -1)},
+		{"floor", []Value{f(1.2, -1.2)}, f(1, -2)},
+		{"round", []Value{f(2.5)}, f(3)},
+		{"roundEven", []Value{f(2.5, 3.5)}, f(2, 4)},
+		{"trunc", []Value{f(-1.7)}, f(-1)},
+		{"fract", []Value{f(-1.25)}, f(0.75)},
+		{"radians", []Value{f(180)}, f(math.Pi)},
+		{"degrees", []Value{f(math.Pi)}, f(180)},
+		{"abs", []Value{f(-1.5)}, f(1.5)},
+		{"abs", []Value{i(-3, 3)}, i(3, 3)},
+		{"sign", []Value{f(-2, 0, 2)}, f(-1, 0, 1)},
+		{"sign", []Value{i(-4)}, i(-1)},
+		{"pow", []Value{f(2), f(10)}, f(1024)},
+		{"mod", []Value{f(-1), f(3)}, f(2)},
+		{"min", []Value{i(3, -2), i(-2)}, i(-2, -2)},
+		{"max", []Value{f(0.5, 2), f(1)}, f(1, 2)},
+		{"clamp", []Value{f(-1, 0.5, 5), f(0), f(1)}, f(0, 0.5, 1)},
+		{"step", []Value{f(0.5), f(0.25, 0.75)}, f(0, 1)},
+		{"mix", []Value{f(0), f(10), f(0.25)}, f(2.5)},
+		{"mix", []Value{f(1, 2), f(3, 4), b(1, 0)}, f(3, 2)},
+		{"smoothstep", []Value{f(0), f(1), f(0.5)}, f(0.5)},
+		{"isnan", []Value{f(math.NaN(), 1)}, b(1, 0)},
+		{"isinf", []Value{f(math.Inf(1), 1)}, b(1, 0)},
+		{"not", []Value{b(1, 0)}, b(0, 1)},
+		{"any", []Value{b(0, 1)}, b(1)},
+		{"all", []Value{b(0, 1)}, b(0)},
+		{"equal", []Value{f(1, 2), f(1, 3)}, b(1, 0)},
+		{"notEqual", []Value{f(1, 2), f(1, 3)}, b(0, 1)},
+		{"lessThan", []Value{i(1, 5), i(2, 2)}, b(1, 0)},
+		{"lessThanEqual", []Value{i(2, 5), i(2, 2)}, b(1, 0)},
+		{"greaterThan", []Value{u(1, 5), u(2, 2)}, b(0, 1)},
+		{"greaterThanEqual", []Value{u(2, 5), u(2, 6)}, b(1, 0)},
+		{"floatBitsToInt", []Value{f(1)}, i(0x3f800000)},
+		{"floatBitsToUint", []Value{f(-1)}, u(0xbf800000)},
+		{"intBitsToFloat", []Value{i(0x3f800000)}, f(1)},
+		{"uintBitsToFloat", []Value{u(0x40000000)}, f(2)},
+		{"dot", []Value{f(1, 2, 3), f(4, 5, 6)}, f(32)},
+		{"length", []Value{f(3, 4)}, f(5)},
+		{"distance", []Value{f(1, 1), f(4, 5)}, f(5)},
+		{"normalize", []Value{f(3, 4)}, f(0.6, 0.8)},
+		{"cross", []Value{f(1, 0, 0), f(0, 1, 0)}, f(0, 0, 1)},
+		{"reflect", []Value{f(1, -1), f(0, 1)}, f(1, 1)},
+		{"refract", []Value{f(0, -1), f(0, 1), f(1)}, f(0, -1)},
+		{"refract", []Value{f(0.8, -0.6), f(0, 1), f(2)}, f(0, 0)},
+		{"faceforward", []Value{f(1, 2), f(0, -1), f(0, 1)}, f(1, 2)},
+		{"faceforward", []Value{f(1, 2), f(0, 1), f(0, 1)}, f(-1, -2)},
+		{"fwidth", []Value{f(5)}, f(0)},
+		{"matrixCompMult", []Value{mat(2, 1, 2, 3, 4), mat(2, 2, 2, 2, 2)}, mat(2, 2, 4, 6, 8)},
+		{"outerProduct", []Value{f(1, 2), f(3, 4)}, mat(2, 3, 6, 4, 8)},
+		{"transpose", []Value{mat(2, 1, 2, 3, 4)}, mat(2, 1, 3, 2, 4)},
+		{"determinant", []Value{mat(2, 1, 2, 3, 4)}, f(-2)},
+		{"determinant", []Value{mat(3, 2, 0, 0, 0, 3, 0, 0, 0, 4)}, f(24)},
+		{"inverse", []Value{mat(2, 4, 7, 2, 6)}, mat(2, 0.6, -0.7, -0.2, 0.4)},
+		{"packUnorm2x16", []Value{f(0, 1)}, u(0xffff0000)},
+		{"packSnorm2x16", []Value{f(-1, 1)}, u(0x7fff8001)},
+		{"unpackUnorm2x16", []Value{u(0xffff0000)}, f(0, 1)},
+		{"unpackSnorm2x16", []Value{u(0x7fff8001)}, f(-1, 1)},
+		{"vec3", []Value{f(2)}, f(2, 2, 2)},
+		{"vec4", []Value{f(1, 2), f(3), f(4)}, f(1, 2, 3, 4)},
+		{"ivec2", []Value{f(1.9, -1.9)}, i(1, -1)},
+		{"uint", []Value{i(-1)}, u(math.MaxUint32)},
+		{"bvec2", []Value{f(0, 2)}, b(0, 1)},
+		{"mat2", []Value{f(3)}, mat(2, 3, 0, 0, 3)},
+		{"mat3", []Value{mat(2, 1, 2, 3, 4)}, mat(3, 1, 2, 0, 3, 4, 0, 0, 0, 1)},
+		{"textureSize", []Value{{Kind: Sampler}, i(0)}, i(0, 0)},
+		{"textureSize", []Value{{Kind: Sampler, Image: image.NewNRGBA(image.Rect(0, 0, 4, 2))}, i(0)}, i(4, 2)},
+		{"texture", []Value{{Kind: Sampler}, f(0.5, 0.5)}, f(0, 0, 0, 0)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := eval(t, call(test.name), test.args...); !same(got, test.want) {
+				t.Fatalf("%s%v = %v, want %v", test.name, test.args, got, test.want)
+			}
+		})
+	}
+}
+
+func TestBuiltinUnsupported(t *testing.T) {
+	m := New()
+	m.Values["x"] = f(1)
+	if _, err := m.Eval(gpu.FunctionCall{Name: "noise", Args: []gpu.Evaluator{gpu.Identifier("x")}}); err == nil {
+		t.Fatal("expected an error for a built-in that is not supported on the CPU")
+	}
+}
+
+func TestFloat32Rounding(t *testing.T) {
+	if got := f(0.1).C[0]; got != float64(float32(0.1)) {
+		t.Fatalf("0.1 = %v, want the float32 %v", got, float64(float32(0.1)))
+	}
+	if got := f(1e39).C[0]; !math.IsInf(got, 1) {
+		t.Fatalf("1e39 = %v, want +Inf as it overflows a float32", got)
+	}
+	for _, test := range []struct {
+		op   string
+		a, b float64
+		want float64
+	}{
+		{"+", 1 << 24, 1, 1 << 24}, // 2^24+1 is not representable as a float32.
+		{"+", 0.1, 0.2, float64(float32(float32(0.1) + float32(0.2)))},
+		{"*", 16777217, 1, 1 << 24},
+		{"/", 1, 3, float64(float32(1.0) / float32(3.0))},
+		{"-", 1, 1e-8, 1},
+	} {
+		got := eval(t, op(test.op), f(test.a), f(test.b))
+		if got.C[0] != test.want {
+			t.Errorf("%v %s %v = %v, want %v", test.a, test.op, test.b, got.C[0], test.want)
+		}
+	}
+	if got := eval(t, call("dot"), f(1<<24, 1), f(1, 1)); got.C[0] != 1<<24 {
+		t.Errorf("dot accumulates as a float64: %v", got.C[0])
+	}
+}
+
+func TestIntegerWraparound(t *testing.T) {
+	for _, test := range []struct {
+		kind Kind
+		op   string
+		a, b float64
+		want float64
+	}{
+		{Int, "+", math.MaxInt32, 1, math.MinInt32},
+		{Int, "-", math.MinInt32, 1, math.MaxInt32},
+		{Int, "*", 1 << 16, 1 << 16, 0},
+		{Int, "*", math.MaxInt32, 2, -2},
+		{Int, "/", 7, -2, -3},
+		{Int, "%", -7, 2, -1},
+		{Int, "/", 1, 0, 0},
+		{Uint, "+", math.MaxUint32, 1, 0},
+		{Uint, "-", 0, 1, math.MaxUint32},
+		{Uint, "*", 1 << 16, 1 << 16, 0},
+		{Uint, "/", 7, 2, 3},
+		{Uint, "%", 7, 0, 0},
+	} {
+		got := eval(t, op(test.op), Vector(test.kind, test.a), Vector(test.kind, test.b))
+		if got.Kind != test.kind || got.C[0] != test.want {
+			t.Errorf("%s: %v %s %v = %v, want %v", Vector(test.kind, 0).TypeName(), test.a, test.op, test.b, got.C[0], test.want)
+		}
+	}
+	for _, test := range []struct {
+		kind Kind
+		x    float64
+		want float64
+	}{
+		{Int, 1 << 32, 0},
+		{Int, 1<<31 + 5, math.MinInt32 + 5},
+		{Int, -2.7, -2},
+		{Int, math.NaN(), 0},
+		{Uint, -1, math.MaxUint32},
+		{Uint, 1<<32 + 5, 5},
+		{Uint, math.Inf(1), 0},
+	} {
+		if got := Scalar(test.kind, test.x).C[0]; got != test.want {
+			t.Errorf("%s(%v) = %v, want %v", Vector(test.kind, 0).TypeName(), test.x, got, test.want)
+		}
+	}
+	if got := eval(t, func(args ...gpu.Evaluator) gpu.Evaluator { return gpu.Operation{Op: "-", B: args[0]} }, i(math.MinInt32)); got.C[0] != math.MinInt32 {
+		t.Errorf("-MinInt32 = %v, want MinInt32", got.C[0])
+	}
+}
@@ -0,0 +1,279 @@
+// Package cpu evaluates the expressions and statements recorded by the shaders package on the
+// CPU, with the same float32, int32 and uint32 semantics as the GPU.
+package cpu
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Kind of each component of a [Value].
+type Kind int
+
+const (
+	Float Kind = iota
+	Int
+	Uint
+	Bool
+	Sampler
+)
+
+// Value is a GLSL scalar, vector, matrix or sampler. Matrices are stored in column-major order.
+type Value struct {
+	Kind  Kind
+	Rows  int         // number of components in each column.
+	Cols  int         // number of columns, 1 for scalars and vectors.
+	C     []float64   // components, already rounded to their kind.
+	Image image.Image // texture of a sampler, nil samples as zero.
+}
+
+// Scalar returns a scalar value of the given kind.
+func Scalar(kind Kind, x float64) Value {
+	return Value{Kind: kind, Rows: 1, Cols: 1, C: []float64{round(kind, x)}}
+}
+
+// Vector returns a vector (or scalar) value of the given kind.
+func Vector(kind Kind, components ...float64) Value {
+	v := Value{Kind: kind, Rows: len(components), Cols: 1, C: make([]float64, len(components))}
+	for i, x := range components {
+		v.C[i] = round(kind, x)
+	}
+	return v
+}
+
+// round the component to the precision of its kind.
+func round(kind Kind, x float64) float64 {
+	switch kind {
+	case Float:
+		return float64(float32(x))
+	case Int:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return 0
+		}
+		return float64(int32(int64(x)))
+	case Uint:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return 0
+		}
+		return float64(uint32(int64(x)))
+	case Bool:
+		if x != 0 {
+			return 1
+		}
+		return 0
+	default:
+		return x
+	}
+}
+
+// Zero returns the zero value of the given GLSL type.
+func Zero(glsl string) (Value, error) {
+	kind, rows, cols, ok := parse(glsl)
+	if !ok {
+		return Value{}, fmt.Errorf("unsupported type %s", glsl)
+	}
+	return Value{Kind: kind, Rows: rows, Cols: cols, C: make([]float64, rows*cols)}, nil
+}
+
+// parse the GLSL type name into its kind and shape.
+func parse(glsl string) (kind Kind, rows, cols int, ok bool) {
+	switch glsl {
+	case "float":
+		return Float, 1, 1, true
+	case "int":
+		return Int, 1, 1, true
+	case "uint":
+		return Uint, 1, 1, true
+	case "bool":
+		return Bool, 1, 1, true
+	}
+	if strings.Contains(glsl, "sampler") {
+		return Sampler, 0, 0, true
+	}
+	if len(glsl) < 4 {
+		return 0, 0, 0, false
+	}
+	n := int(glsl[len(glsl)-1] - '0')
+	if n < 2 || n > 4 {
+		return 0, 0, 0, false
+	}
+	switch glsl[:len(glsl)-1] {
+	case "vec":
+		return Float, n, 1, true
+	case "ivec":
+		return Int, n, 1, true
+	case "uvec":
+		return Uint, n, 1, true
+	case "bvec":
+		return Bool, n, 1, true
+	case "mat":
+		return Float, n, n, true
+	}
+	return 0, 0, 0, false
+}
+
+// TypeName returns the GLSL type name of the value.
+func (v Value) TypeName() string {
+	if v.Kind == Sampler {
+		return "sampler2D"
+	}
+	if v.Cols > 1 {
+		return fmt.Sprintf("mat%d", v.Cols)
+	}
+	prefix := [...]string{Float: "", Int: "i", Uint: "u", Bool: "b"}[v.Kind]
+	if v.Rows == 1 {
+		return [...]string{Float: "float", Int: "int", Uint: "uint", Bool: "bool"}[v.Kind]
+	}
+	return fmt.Sprintf("%svec%d", prefix, v.Rows)
+}
+
+// swizzle selects the named components of a vector, ie. "xy" or "rgb".
+func (v Value) swizzle(components string) (Value, error) {
+	out := Value{Kind: v.Kind, Rows: len(components), Cols: 1}
+	for _, c := range components {
+		i := strings.IndexRune("xyzw", c)
+		if i < 0 {
+			i = strings.IndexRune("rgba", c)
+		}
+		if i < 0 {
+			i = strings.IndexRune("stpq", c)
+		}
+		if i < 0 || i >= len(v.C) || v.Cols > 1 {
+			return Value{}, fmt.Errorf("invalid swizzle .%s of %s", components, v.TypeName())
+		}
+		out.C = append(out.C, v.C[i])
+	}
+	return out, nil
+}
+
+// ValueOf converts the Go value into a [Value] of the given GLSL type, the Go value may be any
+// number, bool, struct or array of them (such as a Vector3.XYZ or a Color.RGBA) or an image.Image
+// for a sampler. If the type is empty, then it is inferred from the Go value.
+func ValueOf(value any, glsl string) (Value, error) {
+	if img, ok := value.(image.Image); ok {
+		if glsl != "" && !strings.Contains(glsl, "sampler") {
+			return Value{}, fmt.Errorf("cannot use an image as a %s", glsl)
+		}
+		return Value{Kind: Sampler, Image: img}, nil
+	}
+	var components []float64
+	var kinds []Kind
+	if err := flatten(reflect.ValueOf(value), &components, &kinds); err != nil {
+		return Value{}, err
+	}
+	if len(components) == 0 {
+		return Value{}, fmt.Errorf("cannot use %T as a GPU value", value)
+	}
+	kind, rows, cols := kinds[0], len(components), 1
+	switch len(components) {
+	case 9:
+		rows, cols = 3, 3
+	case 16:
+		rows, cols = 4, 4
+	}
+	if glsl != "" {
+		var ok bool
+		kind, rows, cols, ok = parse(glsl)
+		if !ok || kind == Sampler {
+			return Value{}, fmt.Errorf("cannot use %T as a %s", value, glsl)
+		}
+		if rows*cols != len(components) {
+			return Value{}, fmt.Errorf("cannot use %T as a %s, expected %d components", value, glsl, rows*cols)
+		}
+	}
+	out := Value{Kind: kind, Rows: rows, Cols: cols, C: components}
+	for i := range out.C {
+		out.C[i] = round(kind, out.C[i])
+	}
+	return out, nil
+}
+
+func flatten(value reflect.Value, components *[]float64, kinds *[]Kind) error {
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		*components = append(*components, value.Float())
+		*kinds = append(*kinds, Float)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		*components = append(*components, float64(value.Int()))
+		*kinds = append(*kinds, Int)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		*components = append(*components, float64(value.Uint()))
+		*kinds = append(*kinds, Uint)
+	case reflect.Bool:
+		*components = append(*components, boolean(value.Bool()))
+		*kinds = append(*kinds, Bool)
+	case reflect.Struct:
+		for i := range value.NumField() {
+			if err := flatten(value.Field(i), components, kinds); err != nil {
+				return err
+			}
+		}
+	case reflect.Array:
+		for i := range value.Len() {
+			if err := flatten(value.Index(i), components, kinds); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cannot use %v as a GPU value", value.Type())
+	}
+	return nil
+}
+
+// Decode the components of the value into the Go value, which must have the same number of
+// numeric or bool components.
+func (v Value) Decode(into reflect.Value) error {
+	if v.Kind == Sampler {
+		return fmt.Errorf("cannot decode a sampler into %v", into.Type())
+	}
+	rest, err := decode(v.C, into)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("cannot decode a %s into %v", v.TypeName(), into.Type())
+	}
+	return nil
+}
+
+func decode(components []float64, into reflect.Value) ([]float64, error) {
+	switch into.Kind() {
+	case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Bool:
+		if len(components) == 0 {
+			return nil, fmt.Errorf("not enough components to decode into %v", into.Type())
+		}
+		switch into.Kind() {
+		case reflect.Float32, reflect.Float64:
+			into.SetFloat(components[0])
+		case reflect.Bool:
+			into.SetBool(components[0] != 0)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			into.SetUint(uint64(components[0]))
+		default:
+			into.SetInt(int64(components[0]))
+		}
+		return components[1:], nil
+	case reflect.Struct:
+		var err error
+		for i := range into.NumField() {
+			if components, err = decode(components, into.Field(i)); err != nil {
+				return nil, err
+			}
+		}
+		return components, nil
+	case reflect.Array:
+		var err error
+		for i := range into.Len() {
+			if components, err = decode(components, into.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		return components, nil
+	default:
+		return nil, fmt.Errorf("cannot decode into %v", into.Type())
+	}
+}
@@ -107,6 +107,16 @@ func (f FunctionCall) evaluate() Evaluator {
 	return f
 }
 
+// Index selects the element at the given index of an array.
+type Index struct {
+	Array Evaluator
+	Index Evaluator
+}
+
+func (i Index) evaluate() Evaluator {
+	return i
+}
+
 type Output struct {
 	Index *int
 	Type  string
@@ -0,0 +1,97 @@
+package gpu
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TypeName returns the GLSL type name for the given GPU type.
+func TypeName(t reflect.Type) string {
+	switch {
+	case t.ConvertibleTo(reflect.TypeFor[Bool]()):
+		return "bool"
+	case t.ConvertibleTo(reflect.TypeFor[Vec2b]()):
+		return "bvec2"
+	case t.ConvertibleTo(reflect.TypeFor[Vec3b]()):
+		return "bvec3"
+	case t.ConvertibleTo(reflect.TypeFor[Vec4b]()):
+		return "bvec4"
+	case t.ConvertibleTo(reflect.TypeFor[Float]()):
+		return "float"
+	case t.ConvertibleTo(reflect.TypeFor[Int]()):
+		return "int"
+	case t.ConvertibleTo(reflect.TypeFor[Vec2i]()):
+		return "ivec2"
+	case t.ConvertibleTo(reflect.TypeFor[Vec3i]()):
+		return "ivec3"
+	case t.ConvertibleTo(reflect.TypeFor[Vec4i]()):
+		return "ivec4"
+	case t.ConvertibleTo(reflect.TypeFor[Mat2]()):
+		return "mat2"
+	case t.ConvertibleTo(reflect.TypeFor[Mat3]()):
+		return "mat3"
+	case t.ConvertibleTo(reflect.TypeFor[Mat4]()):
+		return "mat4"
+	case t.ConvertibleTo(reflect.TypeFor[Vec2]()):
+		return "vec2"
+	case t.ConvertibleTo(reflect.TypeFor[Vec3]()):
+		return "vec3"
+	case t.ConvertibleTo(reflect.TypeFor[Vec4]()):
+		return "vec4"
+	case t.ConvertibleTo(reflect.TypeFor[RGB]()):
+		return "vec3"
+	case t.ConvertibleTo(reflect.TypeFor[RGBA]()):
+		return "vec4"
+	case t.ConvertibleTo(reflect.TypeFor[Uint]()):
+		return "uint"
+	case t.ConvertibleTo(reflect.TypeFor[Vec2u]()):
+		return "uvec2"
+	case t.ConvertibleTo(reflect.TypeFor[Vec3u]()):
+		return "uvec3"
+	case t.ConvertibleTo(reflect.TypeFor[Vec4u]()):
+		return "uvec4"
+	case t.ConvertibleTo(reflect.TypeFor[Vec2]()):
+		return "vec2"
+	case t.ConvertibleTo(reflect.TypeFor[Vec3]()):
+		return "vec3"
+	case t.ConvertibleTo(reflect.TypeFor[Vec4]()):
+		return "vec4"
+	case t.Implements(reflect.TypeFor[IsSampler2D]()):
+		elem := SamplerType(reflect.Zero(t).Interface().(IsSampler2D))
+		switch {
+		case elem.ConvertibleTo(reflect.TypeFor[Vec4]()), elem.ConvertibleTo(reflect.TypeFor[RGBA]()):
+			return "sampler2D"
+		case elem.ConvertibleTo(reflect.TypeFor[Vec4i]()):
+			return "isampler2D"
+		case elem.ConvertibleTo(reflect.TypeFor[Vec4u]()):
+			return "usampler2D"
+		}
+	case t.Implements(reflect.TypeFor[IsSampler3D]()):
+		elem := SamplerType(reflect.Zero(t).Interface().(IsSampler3D))
+		switch {
+		case elem.ConvertibleTo(reflect.TypeFor[Vec4]()), elem.ConvertibleTo(reflect.TypeFor[RGBA]()):
+			return "sampler3D"
+		case elem.ConvertibleTo(reflect.TypeFor[Vec4i]()):
+			return "isampler3D"
+		case elem.ConvertibleTo(reflect.TypeFor[Vec4u]()):
+			return "usampler3D"
+		}
+	case t.Implements(reflect.TypeFor[IsArraySampler2D]()):
+		elem := SamplerType(reflect.Zero(t).Interface().(IsArraySampler2D))
+		switch {
+		case elem.ConvertibleTo(reflect.TypeFor[Vec4]()), elem.ConvertibleTo(reflect.TypeFor[RGBA]()):
+			return "sampler2DArray"
+		case elem.ConvertibleTo(reflect.TypeFor[Vec4i]()):
+			return "isampler2DArray"
+		case elem.ConvertibleTo(reflect.TypeFor[Vec4u]()):
+			return "usampler2DArray"
+		}
+	case t.Implements(reflect.TypeFor[IsCubeSampler]()):
+		return "samplerCube"
+	case t.Implements(reflect.TypeFor[IsCubeArraySampler]()):
+		return "samplerCubeArray"
+	case t.Implements(reflect.TypeFor[IsExternalSampler]()):
+		return "samplerExternalOES"
+	}
+	panic(fmt.Sprintf("unsupported GPU type %s", t))
+}
@@ -0,0 +1,281 @@
+package gpu
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Statement is a GLSL statement, recorded while a pipeline function runs, that is emitted
+// before the outputs of the function are assigned.
+type Statement interface {
+	statement()
+}
+
+// Declare a local variable with the given initial value.
+type Declare struct {
+	Name  Identifier
+	Type  reflect.Type
+	Value Evaluator
+}
+
+// Assign a new value to a local variable.
+type Assign struct {
+	Name  Identifier
+	Value Evaluator
+}
+
+// Store a value into the target, such as an element of a buffer.
+type Store struct {
+	Target Evaluator
+	Value  Evaluator
+}
+
+// Eval evaluates the value for its side effects, such as a call to imageStore.
+type Eval struct {
+	Value Evaluator
+}
+
+// If runs Then when the condition is true, otherwise Else.
+type If struct {
+	Cond Evaluator
+	Then []Statement
+	Else []Statement
+}
+
+// Loop runs Body Count times, with Index counting up from zero.
+type Loop struct {
+	Index Identifier
+	Count Evaluator
+	Body  []Statement
+}
+
+type (
+	Break    struct{} // Break out of the innermost loop.
+	Continue struct{} // Continue with the next iteration of the innermost loop.
+	Discard  struct{} // Discard the current pixel.
+)
+
+func (Declare) statement()  {}
+func (Assign) statement()   {}
+func (Store) statement()    {}
+func (Eval) statement()     {}
+func (If) statement()       {}
+func (Loop) statement()     {}
+func (Break) statement()    {}
+func (Continue) statement() {}
+func (Discard) statement()  {}
+
+// recorder collects the statements of the pipeline function that is currently running.
+type recorder struct {
+	block     *[]Statement
+	loops     int
+	locals    int
+	discard   bool
+	variables map[Identifier]bool // locals that can be assigned to.
+}
+
+var recording *recorder
+
+// Record runs fn and returns the statements it recorded. If discard is true, then
+// the [Discard] statement is permitted.
+func Record(discard bool, fn func()) []Statement {
+	var block []Statement
+	parent := recording
+	recording = &recorder{block: &block, discard: discard}
+	defer func() { recording = parent }()
+	fn()
+	return block
+}
+
+func recorderFor(construct string) *recorder {
+	if recording == nil {
+		panic(fmt.Sprintf("%s can only be used inside of a shader pipeline function", construct))
+	}
+	return recording
+}
+
+// Local returns a new unique identifier for a local variable (or loop index).
+func Local(construct string) Identifier {
+	rec := recorderFor(construct)
+	rec.locals++
+	return Identifier(fmt.Sprintf("_v%d", rec.locals))
+}
+
+// Variable returns a new unique identifier for a local variable that can be assigned to, values
+// derived from it are fixed by the New*Expression functions.
+func Variable(construct string) Identifier {
+	name := Local(construct)
+	rec := recording
+	if rec.variables == nil {
+		rec.variables = make(map[Identifier]bool)
+	}
+	rec.variables[name] = true
+	return name
+}
+
+// fix returns the expression, unless it reads a [Variable], in which case the expression is
+// declared as a new local, so that it keeps the value the variable has at this point of the
+// shader, even when the variable is assigned to later on.
+func fix(e Expression, rtype reflect.Type) Expression {
+	rec := recording
+	if rec == nil || len(rec.variables) == 0 {
+		return e
+	}
+	if _, ok := e.indirect.(Identifier); ok || !rec.reads(e) {
+		return e // the variable itself is read wherever it is used.
+	}
+	name := Local("shaders.Var")
+	Emit("shaders.Var", Declare{Name: name, Type: rtype, Value: e})
+	return New(name)
+}
+
+// reads reports whether the expression reads any of the variables.
+func (rec *recorder) reads(e Evaluator) bool {
+	if e == nil {
+		return false
+	}
+	switch value := e.evaluate().(type) {
+	case nil:
+		return rec.readsComponents(reflect.ValueOf(e))
+	case Identifier:
+		name, _, _ := strings.Cut(string(value), ".")
+		return rec.variables[Identifier(name)]
+	case Operation:
+		return rec.reads(value.A) || rec.reads(value.B)
+	case Ternary:
+		return rec.reads(value.If) || rec.reads(value.A) || rec.reads(value.B)
+	case FunctionCall:
+		return slices.ContainsFunc(value.Args, rec.reads)
+	case Index:
+		return rec.reads(value.Array) || rec.reads(value.Index)
+	default:
+		return false
+	}
+}
+
+// readsComponents reports whether any of the components of a vector or matrix value read any of
+// the variables.
+func (rec *recorder) readsComponents(value reflect.Value) bool {
+	component := func(value reflect.Value) bool {
+		if e, ok := value.Interface().(Evaluator); ok {
+			return rec.reads(e)
+		}
+		return rec.readsComponents(value)
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := range value.NumField() {
+			if field := value.Field(i); !value.Type().Field(i).Anonymous && field.CanInterface() && component(field) {
+				return true
+			}
+		}
+	case reflect.Array:
+		for i := range value.Len() {
+			if component(value.Index(i)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Emit records the statement into the current block.
+func Emit(construct string, stmt Statement) {
+	rec := recorderFor(construct)
+	switch stmt.(type) {
+	case Break, Continue:
+		if rec.loops == 0 {
+			panic(fmt.Sprintf("%s can only be used inside of a loop", construct))
+		}
+	case Discard:
+		if !rec.discard {
+			panic(fmt.Sprintf("%s can only be used by the Material stage of a pipeline", construct))
+		}
+	}
+	*rec.block = append(*rec.block, stmt)
+}
+
+// Block runs fn and returns the statements it recorded, if loop is true, then
+// [Break] and [Continue] are permitted inside of fn.
+func Block(construct string, loop bool, fn func()) []Statement {
+	rec := recorderFor(construct)
+	if fn == nil {
+		return nil
+	}
+	var block []Statement
+	parent := rec.block
+	rec.block = &block
+	if loop {
+		rec.loops++
+	}
+	defer func() {
+		rec.block = parent
+		if loop {
+			rec.loops--
+		}
+	}()
+	fn()
+	return block
+}
+
+// Function is a user-defined GLSL function.
+type Function struct {
+	Name   Identifier
+	Params []Declare // Value is unused.
+	Result reflect.Type
+	Body   []Statement
+	Return Evaluator
+}
+
+// definitions of the user-defined functions called by the program that is currently being
+// compiled.
+type definitions struct {
+	functions []Function
+	names     map[any]Identifier
+	taken     map[Identifier]bool
+	active    map[any]bool
+}
+
+var defining *definitions
+
+// Definitions runs fn and returns the user-defined functions that were called within it,
+// ordered such that each function is defined before it is called.
+func Definitions(fn func()) []Function {
+	parent := defining
+	defining = &definitions{
+		names:  make(map[any]Identifier),
+		taken:  make(map[Identifier]bool),
+		active: make(map[any]bool),
+	}
+	defer func() { defining = parent }()
+	fn()
+	return defining.functions
+}
+
+// Define returns the name of the user-defined function identified by key, defining it on first
+// use by calling record with its unique name.
+func Define(key any, name string, record func(Identifier) Function) Identifier {
+	defs := defining
+	if defs == nil {
+		panic(fmt.Sprintf("shaders.Func: %s can only be called inside of a shader pipeline function", name))
+	}
+	if defs.active[key] {
+		panic(fmt.Sprintf("shaders.Func: %s calls itself recursively, which is not supported by shaders", name))
+	}
+	if id, ok := defs.names[key]; ok {
+		return id
+	}
+	id := Identifier(name)
+	for i := 2; defs.taken[id]; i++ {
+		id = Identifier(fmt.Sprintf("%s%d", name, i))
+	}
+	defs.taken[id] = true
+	defs.active[key] = true
+	defer delete(defs.active, key)
+	fn := record(id)
+	defs.names[key] = id
+	defs.functions = append(defs.functions, fn)
+	return id
+}
@@ -4,6 +4,8 @@ import (
 	"reflect"
 
 	"graphics.gd/classdb/Cubemap"
+	"graphics.gd/classdb/CubemapArray"
+	"graphics.gd/classdb/ExternalTexture"
 	"graphics.gd/classdb/Texture2D"
 	"graphics.gd/classdb/Texture2DArray"
 	"graphics.gd/classdb/Texture3D"
@@ -25,6 +27,23 @@ type isEquivalentTo[T any] interface {
 	equivalentTo(T)
 }
 
+// EquivalentType returns the Go type that the GPU type is equivalent to, ie. Vector3.XYZ for a Vec3.
+func EquivalentType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.Anonymous || field.Type.Kind() != reflect.Interface || field.Type.NumMethod() != 1 {
+			continue
+		}
+		if method := field.Type.Method(0); method.Name == "equivalentTo" {
+			return method.Type.In(0), true
+		}
+	}
+	return nil, false
+}
+
 type Sampler2D[T any] struct {
 	internalExpression
 	isEquivalentTo[Texture2D.Instance]
@@ -79,6 +98,28 @@ type IsCubeSampler interface {
 	cubeSampler() reflect.Type
 }
 
+type CubeArraySampler[T any] struct {
+	internalExpression
+	isEquivalentTo[CubemapArray.Instance]
+}
+
+func (CubeArraySampler[T]) cubeArraySampler() reflect.Type { return reflect.TypeFor[T]() }
+
+type IsCubeArraySampler interface {
+	cubeArraySampler() reflect.Type
+}
+
+type ExternalSampler[T any] struct {
+	internalExpression
+	isEquivalentTo[ExternalTexture.Instance]
+}
+
+func (ExternalSampler[T]) externalSampler() reflect.Type { return reflect.TypeFor[T]() }
+
+type IsExternalSampler interface {
+	externalSampler() reflect.Type
+}
+
 func SamplerType(val any) reflect.Type {
 	switch v := val.(type) {
 	case IsSampler2D:
@@ -91,6 +132,10 @@ func SamplerType(val any) reflect.Type {
 		return v.arraySampler3D()
 	case IsCubeSampler:
 		return v.cubeSampler()
+	case IsCubeArraySampler:
+		return v.cubeArraySampler()
+	case IsExternalSampler:
+		return v.externalSampler()
 	default:
 		panic("unsupported sampler type " + reflect.TypeOf(v).String())
 	}
@@ -115,7 +160,9 @@ func NewBool[T AnyBool](x T) Bool {
 	return rvalue.Convert(reflect.TypeFor[Bool]()).Interface().(Bool)
 }
 
-func NewBoolExpression(e Expression) Bool { return Bool{internalExpression: e} }
+func NewBoolExpression(e Expression) Bool {
+	return Bool{internalExpression: fix(e, reflect.TypeFor[Bool]())}
+}
 
 type Int = struct {
 	internalExpression
@@ -135,7 +182,9 @@ func NewInt[T AnyInt](x T) Int {
 	}
 	return rvalue.Convert(reflect.TypeFor[Int]()).Interface().(Int)
 }
-func NewIntExpression(e Expression) Int { return Int{internalExpression: e} }
+func NewIntExpression(e Expression) Int {
+	return Int{internalExpression: fix(e, reflect.TypeFor[Int]())}
+}
 
 type Uint = struct {
 	internalExpression
@@ -155,7 +204,9 @@ func NewUint[T AnyUint](x T) Uint {
 	}
 	return rvalue.Convert(reflect.TypeFor[Uint]()).Interface().(Uint)
 }
-func NewUintExpression(e Expression) Uint { return Uint{internalExpression: e} }
+func NewUintExpression(e Expression) Uint {
+	return Uint{internalExpression: fix(e, reflect.TypeFor[Uint]())}
+}
 
 type Float = struct {
 	internalExpression
@@ -175,7 +226,9 @@ func NewFloat[T AnyFloat](x T) Float {
 	}
 	return rvalue.Convert(reflect.TypeFor[Float]()).Interface().(Float)
 }
-func NewFloatExpression(e Expression) Float { return Float{internalExpression: e} }
+func NewFloatExpression(e Expression) Float {
+	return Float{internalExpression: fix(e, reflect.TypeFor[Float]())}
+}
 
 type Vec2b = struct {
 	internalExpression
@@ -185,7 +238,9 @@ type Vec2b = struct {
 }
 
 func NewVec2b[X, Y AnyBool](x X, y Y) Vec2b { return Vec2b{X: NewBool(x), Y: NewBool(y)} }
-func NewVec2bExpression(e Expression) Vec2b { return Vec2b{internalExpression: e} }
+func NewVec2bExpression(e Expression) Vec2b {
+	return Vec2b{internalExpression: fix(e, reflect.TypeFor[Vec2b]())}
+}
 
 type Vec3b = struct {
 	internalExpression
@@ -197,7 +252,9 @@ type Vec3b = struct {
 func NewVec3b[X, Y, Z AnyBool](x X, y Y, z Z) Vec3b {
 	return Vec3b{X: NewBool(x), Y: NewBool(y), Z: NewBool(z)}
 }
-func NewVec3bExpression(e Expression) Vec3b { return Vec3b{internalExpression: e} }
+func NewVec3bExpression(e Expression) Vec3b {
+	return Vec3b{internalExpression: fix(e, reflect.TypeFor[Vec3b]())}
+}
 
 type Vec4b = struct {
 	internalExpression
@@ -209,7 +266,9 @@ type Vec4b = struct {
 func NewVec4b[X, Y, Z, W AnyBool](x X, y Y, z Z, w W) Vec4b {
 	return Vec4b{X: NewBool(x), Y: NewBool(y), Z: NewBool(z), W: NewBool(w)}
 }
-func NewVec4bExpression(e Expression) Vec4b { return Vec4b{internalExpression: e} }
+func NewVec4bExpression(e Expression) Vec4b {
+	return Vec4b{internalExpression: fix(e, reflect.TypeFor[Vec4b]())}
+}
 
 type Vec2i = struct {
 	internalExpression
@@ -218,8 +277,10 @@ type Vec2i = struct {
 	X, Y Int
 }
 
-func NewVec2i[X, Y AnyInt](x X, y Y) Vec2i  { return Vec2i{X: NewInt(x), Y: NewInt(y)} }
-func NewVec2iExpression(e Expression) Vec2i { return Vec2i{internalExpression: e} }
+func NewVec2i[X, Y AnyInt](x X, y Y) Vec2i { return Vec2i{X: NewInt(x), Y: NewInt(y)} }
+func NewVec2iExpression(e Expression) Vec2i {
+	return Vec2i{internalExpression: fix(e, reflect.TypeFor[Vec2i]())}
+}
 
 type Vec3i = struct {
 	internalExpression
@@ -231,7 +292,9 @@ type Vec3i = struct {
 func NewVec3i[X, Y, Z AnyInt](x X, y Y, z Z) Vec3i {
 	return Vec3i{X: NewInt(x), Y: NewInt(y), Z: NewInt(z)}
 }
-func NewVec3iExpression(e Expression) Vec3i { return Vec3i{internalExpression: e} }
+func NewVec3iExpression(e Expression) Vec3i {
+	return Vec3i{internalExpression: fix(e, reflect.TypeFor[Vec3i]())}
+}
 
 type Vec4i = struct {
 	internalExpression
@@ -243,7 +306,9 @@ type Vec4i = struct {
 func NewVec4i[X, Y, Z, W AnyInt](x X, y Y, z Z, w W) Vec4i {
 	return Vec4i{X: NewInt(x), Y: NewInt(y), Z: NewInt(z), W: NewInt(w)}
 }
-func NewVec4iExpression(e Expression) Vec4i { return Vec4i{internalExpression: e} }
+func NewVec4iExpression(e Expression) Vec4i {
+	return Vec4i{internalExpression: fix(e, reflect.TypeFor[Vec4i]())}
+}
 
 type Vec2u = struct {
 	internalExpression
@@ -253,7 +318,9 @@ type Vec2u = struct {
 }
 
 func NewVec2u[X, Y AnyUint](x X, y Y) Vec2u { return Vec2u{X: NewUint(x), Y: NewUint(y)} }
-func NewVec2uExpression(e Expression) Vec2u { return Vec2u{internalExpression: e} }
+func NewVec2uExpression(e Expression) Vec2u {
+	return Vec2u{internalExpression: fix(e, reflect.TypeFor[Vec2u]())}
+}
 
 type Vec3u = struct {
 	internalExpression
@@ -265,7 +332,9 @@ type Vec3u = struct {
 func NewVec3u[X, Y, Z AnyUint](x X, y Y, z Z) Vec3u {
 	return Vec3u{X: NewUint(x), Y: NewUint(y), Z: NewUint(z)}
 }
-func NewVec3uExpression(e Expression) Vec3u { return Vec3u{internalExpression: e} }
+func NewVec3uExpression(e Expression) Vec3u {
+	return Vec3u{internalExpression: fix(e, reflect.TypeFor[Vec3u]())}
+}
 
 type Vec4u = struct {
 	internalExpression
@@ -277,7 +346,9 @@ type Vec4u = struct {
 func NewVec4u[X, Y, Z, W AnyUint](x X, y Y, z Z, w W) Vec4u {
 	return Vec4u{X: NewUint(x), Y: NewUint(y), Z: NewUint(z), W: NewUint(w)}
 }
-func NewVec4uExpression(e Expression) Vec4u { return Vec4u{internalExpression: e} }
+func NewVec4uExpression(e Expression) Vec4u {
+	return Vec4u{internalExpression: fix(e, reflect.TypeFor[Vec4u]())}
+}
 
 type Vec2 = struct {
 	internalExpression
@@ -287,7 +358,9 @@ type Vec2 = struct {
 }
 
 func NewVec2[X, Y AnyFloat](x X, y Y) Vec2 { return Vec2{X: NewFloat(x), Y: NewFloat(y)} }
-func NewVec2Expression(e Expression) Vec2  { return Vec2{internalExpression: e} }
+func NewVec2Expression(e Expression) Vec2 {
+	return Vec2{internalExpression: fix(e, reflect.TypeFor[Vec2]())}
+}
 
 type Vec3 = struct {
 	internalExpression
@@ -299,7 +372,9 @@ type Vec3 = struct {
 func NewVec3[X, Y, Z AnyFloat](x X, y Y, z Z) Vec3 {
 	return Vec3{X: NewFloat(x), Y: NewFloat(y), Z: NewFloat(z)}
 }
-func NewVec3Expression(e Expression) Vec3 { return Vec3{internalExpression: e} }
+func NewVec3Expression(e Expression) Vec3 {
+	return Vec3{internalExpression: fix(e, reflect.TypeFor[Vec3]())}
+}
 
 type Vec4 = struct {
 	internalExpression
@@ -311,7 +386,9 @@ type Vec4 = struct {
 func NewVec4[X, Y, Z, W AnyFloat](x X, y Y, z Z, w W) Vec4 {
 	return Vec4{X: NewFloat(x), Y: NewFloat(y), Z: NewFloat(z), W: NewFloat(w)}
 }
-func NewVec4Expression(e Expression) Vec4 { return Vec4{internalExpression: e} }
+func NewVec4Expression(e Expression) Vec4 {
+	return Vec4{internalExpression: fix(e, reflect.TypeFor[Vec4]())}
+}
 
 type RGBA = struct {
 	internalExpression
@@ -323,7 +400,9 @@ type RGBA = struct {
 func NewRGBA[R, G, B, A AnyFloat](r R, g G, b B, a A) RGBA {
 	return RGBA{R: NewFloat(r), G: NewFloat(g), B: NewFloat(b), A: NewFloat(a)}
 }
-func NewRGBAExpression(e Expression) RGBA { return RGBA{internalExpression: e} }
+func NewRGBAExpression(e Expression) RGBA {
+	return RGBA{internalExpression: fix(e, reflect.TypeFor[RGBA]())}
+}
 
 type RGB = struct {
 	internalExpression
@@ -335,7 +414,9 @@ type RGB = struct {
 func NewRGB[R, G, B AnyFloat](r R, g G, b B) RGB {
 	return RGB{R: NewFloat(r), G: NewFloat(g), B: NewFloat(b)}
 }
-func NewRGBExpression(e Expression) RGB { return RGB{internalExpression: e} }
+func NewRGBExpression(e Expression) RGB {
+	return RGB{internalExpression: fix(e, reflect.TypeFor[RGB]())}
+}
 
 type Quad interface {
 	~Vec4 | ~Vec4i | ~Vec4u | ~RGBA
@@ -370,7 +451,9 @@ func NewMat2[C00, C01, C10, C11 AnyFloat](c00 C00, c01 C01, c10 C10, c11 C11) Ma
 		{NewFloat(c10), NewFloat(c11)},
 	}}
 }
-func NewMat2Expression(e Expression) Mat2 { return Mat2{internalExpression: e} }
+func NewMat2Expression(e Expression) Mat2 {
+	return Mat2{internalExpression: fix(e, reflect.TypeFor[Mat2]())}
+}
 
 type Mat3 = struct {
 	internalExpression
@@ -387,7 +470,9 @@ func NewMat3[C00, C01, C02, C10, C11, C12, C20, C21, C22 AnyFloat](c00 C00, c01
 	}}
 }
 
-func NewMat3Expression(e Expression) Mat3 { return Mat3{internalExpression: e} }
+func NewMat3Expression(e Expression) Mat3 {
+	return Mat3{internalExpression: fix(e, reflect.TypeFor[Mat3]())}
+}
 
 type Mat4 = struct {
 	internalExpression
@@ -405,4 +490,6 @@ func NewMat4[C00, C01, C02, C03, C10, C11, C12, C13, C20, C21, C22, C23, C30, C3
 	}}
 }
 
-func NewMat4Expression(e Expression) Mat4 { return Mat4{internalExpression: e} }
+func NewMat4Expression(e Expression) Mat4 {
+	return Mat4{internalExpression: fix(e, reflect.TypeFor[Mat4]())}
+}
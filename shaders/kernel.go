@@ -0,0 +1,26 @@
+package shaders
+
+import (
+	"fmt"
+	"io"
+
+	"graphics.gd/shaders/internal/gpu"
+)
+
+// CompileMain links the fields of the input struct to their GLSL built-ins (as named by their gd
+// tags) and then runs fn, compiling it into a GLSL main function, preceded by any functions it
+// calls. This is intended for pipelines that are not compiled by the engine's shader language,
+// such as compute shaders.
+func CompileMain(w io.Writer, input any, fn func()) {
+	linkup(input)
+	var main []gpu.Statement
+	functions := gpu.Definitions(func() {
+		main = gpu.Record(false, fn)
+	})
+	for _, def := range functions {
+		compileDefinition(w, def)
+	}
+	fmt.Fprintf(w, "void main() {\n")
+	compileStatements(w, main, 1)
+	fmt.Fprintf(w, "}\n")
+}
@@ -4,7 +4,6 @@ package CanvasItem
 import (
 	"reflect"
 
-	"graphics.gd/classdb/ShaderMaterial"
 	"graphics.gd/internal/gdclass"
 	"graphics.gd/shaders"
 	"graphics.gd/shaders/bool"
@@ -24,7 +23,7 @@ CanvasItem shaders contain fewer built-in variables and functionality than Spati
 structure with vertex, fragment, and light processor functions.
 */
 type Shader[T gdclass.Interface] struct {
-	ShaderMaterial.Extension[T]
+	shaders.Extension[T]
 }
 
 func (s *Shader[T]) OnCreate(value reflect.Value) {
@@ -0,0 +1,178 @@
+package Compute
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+
+	"graphics.gd/classdb/RDUniform"
+	"graphics.gd/classdb/Rendering"
+	"graphics.gd/classdb/RenderingDevice"
+	"graphics.gd/shaders/internal/gpu"
+	"graphics.gd/variant/RID"
+)
+
+// Buffer is a storage buffer binding, an array of T that can be read and written by the kernel.
+// T must be a scalar or vector GPU type. The contents of the buffer are uploaded with [Upload] and
+// downloaded with [Download].
+type Buffer[T gpu.Evaluator] struct {
+	name    gpu.Identifier
+	device  RenderingDevice.Instance
+	changed func()
+	rid     RID.StorageBuffer
+	size    int
+}
+
+func (b *Buffer[T]) declare(set, binding int, name string) string {
+	b.name = gpu.Identifier(name)
+	return fmt.Sprintf("layout(set = %d, binding = %d, std430) restrict buffer Buffer%d { %s data[]; } %s;",
+		set, binding, binding, gpu.TypeName(reflect.TypeFor[T]()), name)
+}
+
+func (b *Buffer[T]) attach(device RenderingDevice.Instance, changed func()) {
+	b.device, b.changed = device, changed
+}
+
+func (b *Buffer[T]) uniform(binding int) (RDUniform.Instance, error) {
+	if !RID.Any(b.rid).IsValid() {
+		return RDUniform.Nil, fmt.Errorf("Compute: buffer %s %w", b.name, errUnbound)
+	}
+	uniform := RDUniform.New()
+	uniform.SetUniformType(Rendering.UniformTypeStorageBuffer)
+	uniform.SetBinding(binding)
+	uniform.AddId(RID.Any(b.rid))
+	return uniform, nil
+}
+
+func (b *Buffer[T]) free() {
+	if RID.Any(b.rid).IsValid() {
+		b.device.FreeRid(RID.Any(b.rid))
+		b.rid, b.size = 0, 0
+	}
+}
+
+// Upload the values into the buffer, (re)creating it on the device if the size has changed.
+// Must be called after [New].
+func Upload[T gpu.EquivalentTo[G], G any](buffer *Buffer[T], values []G) error {
+	if buffer.changed == nil {
+		return fmt.Errorf("Compute.Upload: buffer is not part of a compiled pipeline")
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("Compute.Upload: buffer %s cannot be empty", buffer.name)
+	}
+	data := encodeAll[T](values)
+	if len(data) != buffer.size {
+		buffer.free()
+		buffer.rid = RenderingDevice.Expanded(buffer.device).StorageBufferCreate(len(data), data, 0, 0)
+		buffer.size = len(data)
+		buffer.changed()
+		return nil
+	}
+	return buffer.device.BufferUpdate(RID.Buffer(buffer.rid), 0, len(data), data)
+}
+
+// Download the values from the buffer, on a local rendering device this should be called after
+// [Pipeline.Wait].
+func Download[T gpu.EquivalentTo[G], G any](buffer *Buffer[T]) []G {
+	if !RID.Any(buffer.rid).IsValid() {
+		return nil
+	}
+	return decodeAll[T, G](buffer.device.BufferGetData(RID.Buffer(buffer.rid)))
+}
+
+// encodeAll encodes the values as a std430 array of T.
+func encodeAll[T gpu.EquivalentTo[G], G any](values []G) []byte {
+	stride := strideOf(reflect.TypeFor[T]())
+	data := make([]byte, 0, stride*len(values))
+	for _, value := range values {
+		data = encode(data, reflect.ValueOf(value))
+		for len(data)%stride != 0 {
+			data = append(data, 0)
+		}
+	}
+	return data
+}
+
+// decodeAll decodes a std430 array of T into values.
+func decodeAll[T gpu.EquivalentTo[G], G any](data []byte) []G {
+	stride := strideOf(reflect.TypeFor[T]())
+	values := make([]G, len(data)/stride)
+	for i := range values {
+		decode(data[i*stride:(i+1)*stride], reflect.ValueOf(&values[i]).Elem())
+	}
+	return values
+}
+
+// strideOf returns the size of each element of a std430 array of the given GPU type.
+func strideOf(rtype reflect.Type) int {
+	switch gpu.TypeName(rtype) {
+	case "float", "int", "uint", "bool":
+		return 4
+	case "vec2", "ivec2", "uvec2", "bvec2":
+		return 8
+	case "vec3", "ivec3", "uvec3", "bvec3", "vec4", "ivec4", "uvec4", "bvec4":
+		return 16
+	default:
+		panic(fmt.Sprintf("Compute: %v is not supported as a buffer element", rtype))
+	}
+}
+
+// encode appends the Go value to the data, as a sequence of 32-bit components.
+func encode(data []byte, value reflect.Value) []byte {
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(value.Float())))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.LittleEndian.AppendUint32(data, uint32(int32(value.Int())))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return binary.LittleEndian.AppendUint32(data, uint32(value.Uint()))
+	case reflect.Bool:
+		if value.Bool() {
+			return binary.LittleEndian.AppendUint32(data, 1)
+		}
+		return binary.LittleEndian.AppendUint32(data, 0)
+	case reflect.Struct:
+		for i := range value.NumField() {
+			data = encode(data, value.Field(i))
+		}
+		return data
+	case reflect.Array:
+		for i := range value.Len() {
+			data = encode(data, value.Index(i))
+		}
+		return data
+	default:
+		panic(fmt.Sprintf("Compute: cannot upload %v", value.Type()))
+	}
+}
+
+// decode the 32-bit components in data into the Go value, returning the remaining data.
+func decode(data []byte, value reflect.Value) []byte {
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		value.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(data))))
+		return data[4:]
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(int64(int32(binary.LittleEndian.Uint32(data))))
+		return data[4:]
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		value.SetUint(uint64(binary.LittleEndian.Uint32(data)))
+		return data[4:]
+	case reflect.Bool:
+		value.SetBool(binary.LittleEndian.Uint32(data) != 0)
+		return data[4:]
+	case reflect.Struct:
+		for i := range value.NumField() {
+			data = decode(data, value.Field(i))
+		}
+		return data
+	case reflect.Array:
+		for i := range value.Len() {
+			data = decode(data, value.Index(i))
+		}
+		return data
+	default:
+		panic(fmt.Sprintf("Compute: cannot download %v", value.Type()))
+	}
+}
@@ -0,0 +1,64 @@
+package Compute
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"graphics.gd/shaders/internal/gpu"
+	"graphics.gd/variant/Vector2"
+	"graphics.gd/variant/Vector3"
+	"graphics.gd/variant/Vector3i"
+)
+
+// roundTrip encodes the values as a std430 array of T, checks the size of the encoding and then
+// checks that they decode back to the same values.
+func roundTrip[T gpu.EquivalentTo[G], G any](t *testing.T, stride int, values []G) []byte {
+	t.Helper()
+	data := encodeAll[T](values)
+	if len(data) != stride*len(values) {
+		t.Fatalf("encoded %d values of %v into %d bytes, want %d", len(values), reflect.TypeFor[T](), len(data), stride*len(values))
+	}
+	if decoded := decodeAll[T, G](data); !reflect.DeepEqual(decoded, values) {
+		t.Fatalf("decoded %v, want %v", decoded, values)
+	}
+	return data
+}
+
+func TestBufferRoundTrip(t *testing.T) {
+	t.Run("float", func(t *testing.T) {
+		roundTrip[gpu.Float](t, 4, []float32{0, 1.5, -2.25, math.MaxFloat32})
+	})
+	t.Run("int", func(t *testing.T) {
+		roundTrip[gpu.Int](t, 4, []int32{0, -1, math.MinInt32, math.MaxInt32})
+	})
+	t.Run("uint", func(t *testing.T) {
+		roundTrip[gpu.Uint](t, 4, []uint32{0, 1, math.MaxUint32})
+	})
+	t.Run("bool", func(t *testing.T) {
+		data := roundTrip[gpu.Bool](t, 4, []bool{true, false, true})
+		if data[0] != 1 || data[4] != 0 {
+			t.Fatalf("bools are not encoded as 32-bit 0 or 1: %v", data)
+		}
+	})
+	t.Run("vec2", func(t *testing.T) {
+		roundTrip[gpu.Vec2](t, 8, []Vector2.XY{{1, 2}, {-3, 4}})
+	})
+	t.Run("vec3", func(t *testing.T) {
+		data := roundTrip[gpu.Vec3](t, 16, []Vector3.XYZ{{1, 2, 3}, {4, 5, 6}})
+		if math.Float32frombits(uint32(data[16])|uint32(data[17])<<8|uint32(data[18])<<16|uint32(data[19])<<24) != 4 {
+			t.Fatalf("second vec3 does not start at a 16-byte stride: %v", data)
+		}
+		for _, b := range data[12:16] {
+			if b != 0 {
+				t.Fatalf("vec3 padding is not zeroed: %v", data)
+			}
+		}
+	})
+	t.Run("ivec3", func(t *testing.T) {
+		roundTrip[gpu.Vec3i](t, 16, []Vector3i.XYZ{{-1, 0, 1}, {math.MinInt32, math.MaxInt32, 7}})
+	})
+	t.Run("uvec3", func(t *testing.T) {
+		roundTrip[gpu.Vec3u](t, 16, [][3]uint32{{1, 2, 3}, {math.MaxUint32, 0, 9}})
+	})
+}
@@ -0,0 +1,116 @@
+package Compute
+
+import (
+	"fmt"
+	"reflect"
+
+	"graphics.gd/classdb/RDTextureFormat"
+	"graphics.gd/classdb/RDTextureView"
+	"graphics.gd/classdb/RDUniform"
+	"graphics.gd/classdb/Rendering"
+	"graphics.gd/classdb/RenderingDevice"
+	"graphics.gd/shaders/internal/gpu"
+	"graphics.gd/variant/RID"
+)
+
+// AnyPixel is the type of each pixel of an [Image], with 32-bit float, signed or unsigned integer
+// components.
+type AnyPixel interface {
+	gpu.Quad
+	gpu.Evaluator
+}
+
+// Image is a 2D storage image binding, with four 32-bit components per pixel, that can be read
+// and written by the kernel. Use [Image.Create] to create a new image on the device, or
+// [Image.Bind] to use an existing texture.
+type Image[T AnyPixel] struct {
+	name    gpu.Identifier
+	device  RenderingDevice.Instance
+	changed func()
+	rid     RID.Texture
+	owned   bool
+}
+
+// format returns the GLSL image format, the GLSL image type and the equivalent data format.
+func (img *Image[T]) format() (string, string, Rendering.DataFormat) {
+	switch gpu.TypeName(reflect.TypeFor[T]()) {
+	case "ivec4":
+		return "rgba32i", "iimage2D", Rendering.DataFormatR32g32b32a32Sint
+	case "uvec4":
+		return "rgba32ui", "uimage2D", Rendering.DataFormatR32g32b32a32Uint
+	default:
+		return "rgba32f", "image2D", Rendering.DataFormatR32g32b32a32Sfloat
+	}
+}
+
+func (img *Image[T]) declare(set, binding int, name string) string {
+	img.name = gpu.Identifier(name)
+	format, kind, _ := img.format()
+	return fmt.Sprintf("layout(set = %d, binding = %d, %s) uniform restrict %s %s;", set, binding, format, kind, name)
+}
+
+func (img *Image[T]) attach(device RenderingDevice.Instance, changed func()) {
+	img.device, img.changed = device, changed
+}
+
+func (img *Image[T]) uniform(binding int) (RDUniform.Instance, error) {
+	if !RID.Any(img.rid).IsValid() {
+		return RDUniform.Nil, fmt.Errorf("Compute: image %s %w", img.name, errUnbound)
+	}
+	uniform := RDUniform.New()
+	uniform.SetUniformType(Rendering.UniformTypeImage)
+	uniform.SetBinding(binding)
+	uniform.AddId(RID.Any(img.rid))
+	return uniform, nil
+}
+
+func (img *Image[T]) free() {
+	if img.owned && RID.Any(img.rid).IsValid() {
+		img.device.FreeRid(RID.Any(img.rid))
+	}
+	img.rid, img.owned = 0, false
+}
+
+// Create a new image of the given size on the device, which can also be sampled by other shaders
+// through [Image.Texture]. Must be called after [New].
+func (img *Image[T]) Create(width, height int) error {
+	if img.changed == nil {
+		return fmt.Errorf("Compute.Image.Create: image is not part of a compiled pipeline")
+	}
+	_, _, data := img.format()
+	format := RDTextureFormat.New()
+	format.SetWidth(width)
+	format.SetHeight(height)
+	format.SetFormat(data)
+	format.SetUsageBits(Rendering.TextureUsageStorageBit | Rendering.TextureUsageSamplingBit |
+		Rendering.TextureUsageCanUpdateBit | Rendering.TextureUsageCanCopyFromBit)
+	img.free()
+	img.rid = img.device.TextureCreate(format, RDTextureView.New())
+	img.owned = true
+	img.changed()
+	return nil
+}
+
+// Bind an existing texture on the device to the image, the texture must have been created with
+// storage usage and a matching 32-bit, four component format. Must be called after [New].
+func (img *Image[T]) Bind(texture RID.Texture) error {
+	if img.changed == nil {
+		return fmt.Errorf("Compute.Image.Bind: image is not part of a compiled pipeline")
+	}
+	img.free()
+	img.rid = texture
+	img.changed()
+	return nil
+}
+
+// Texture returns the texture on the device that backs the image.
+func (img *Image[T]) Texture() RID.Texture { return img.rid }
+
+// Pixels downloads the raw pixel data of the image, on a local rendering device this should be
+// called after [Pipeline.Wait].
+func (img *Image[T]) Pixels() []byte {
+	if !RID.Any(img.rid).IsValid() {
+		return nil
+	}
+	return img.device.TextureGetData(img.rid, 0)
+}
@@ -0,0 +1,41 @@
+package Compute
+
+import (
+	"graphics.gd/shaders/internal/gpu"
+	"graphics.gd/shaders/ivec2"
+	"graphics.gd/shaders/uint"
+)
+
+func (b Buffer[T]) data() gpu.Identifier { return b.name + ".data" }
+
+// Load returns the element at the given index of the buffer.
+func (b Buffer[T]) Load(index uint.X) T {
+	var value T
+	gpu.Set(any(&value).(gpu.Pointer), gpu.New(gpu.Index{Array: b.data(), Index: index}))
+	return value
+}
+
+// Store the value at the given index of the buffer.
+func (b Buffer[T]) Store(index uint.X, value T) {
+	gpu.Emit("Compute.Buffer.Store", gpu.Store{Target: gpu.Index{Array: b.data(), Index: index}, Value: value})
+}
+
+// Len returns the number of elements in the buffer.
+func (b Buffer[T]) Len() uint.X {
+	return gpu.NewUintExpression(gpu.Fn("uint", b.data()+".length()"))
+}
+
+// Load returns the pixel at the given coordinates of the image.
+func (img Image[T]) Load(coord ivec2.XY) T {
+	return gpu.NewQuadExpression[T](gpu.Fn("imageLoad", img.name, coord))
+}
+
+// Store the pixel at the given coordinates of the image.
+func (img Image[T]) Store(coord ivec2.XY, value T) {
+	gpu.Emit("Compute.Image.Store", gpu.Eval{Value: gpu.Fn("imageStore", img.name, coord, value)})
+}
+
+// Size returns the size of the image in pixels.
+func (img Image[T]) Size() ivec2.XY {
+	return gpu.NewVec2iExpression(gpu.Fn("imageSize", img.name))
+}
@@ -0,0 +1,207 @@
+/*
+Package Compute provides a compute shader pipeline, for running general purpose kernels written in
+Go on the GPU through a RenderingDevice.
+
+A kernel is a struct with a Compute method, its fields are the [Buffer] and [Image] bindings that
+the kernel reads from and writes to:
+
+	type Simulation struct {
+		Positions  Compute.Buffer[vec4.XYZW]
+		Velocities Compute.Buffer[vec4.XYZW]
+	}
+
+	func (sim *Simulation) Compute(id Compute.Invocation) {
+		i := uint.X(id.GlobalID.X)
+		sim.Positions.Store(i, vec4.Add(sim.Positions.Load(i), sim.Velocities.Load(i)))
+	}
+
+	device := RenderingServer.CreateLocalRenderingDevice()
+	sim := new(Simulation)
+	pipeline, err := Compute.New(device, sim, Compute.WorkgroupSize{64, 1, 1})
+	if err != nil {
+		return err
+	}
+	Compute.Upload(&sim.Positions, positions)
+	Compute.Upload(&sim.Velocities, velocities)
+	pipeline.Dispatch(len(positions)/64, 1, 1)
+	pipeline.Wait()
+	positions = Compute.Download(&sim.Positions)
+
+As with the other pipelines, Go branches only run when the kernel is compiled, use [shaders.If]
+and [shaders.Loop] to branch and loop on the GPU.
+*/
+package Compute
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"graphics.gd/classdb/RDShaderSource"
+	"graphics.gd/classdb/RDUniform"
+	"graphics.gd/classdb/Rendering"
+	"graphics.gd/classdb/RenderingDevice"
+	"graphics.gd/shaders"
+	"graphics.gd/shaders/uint"
+	"graphics.gd/shaders/uvec3"
+	"graphics.gd/variant/RID"
+	"graphics.gd/variant/String"
+)
+
+// Kernel is implemented by a pointer to a struct, with [Buffer] and [Image] fields, the Compute
+// method is run once for each invocation of the kernel.
+type Kernel interface {
+	Compute(Invocation)
+}
+
+// Invocation identifies the current invocation of the kernel.
+type Invocation struct {
+	GlobalID      uvec3.XYZ `gd:"gl_GlobalInvocationID"`   // Unique ID of this invocation, across all workgroups.
+	LocalID       uvec3.XYZ `gd:"gl_LocalInvocationID"`    // ID of this invocation within its workgroup.
+	LocalIndex    uint.X    `gd:"gl_LocalInvocationIndex"` // LocalID flattened into a single index.
+	WorkgroupID   uvec3.XYZ `gd:"gl_WorkGroupID"`          // ID of the workgroup this invocation belongs to.
+	NumWorkgroups uvec3.XYZ `gd:"gl_NumWorkGroups"`        // Number of workgroups being dispatched.
+}
+
+// WorkgroupSize is the number of invocations in each workgroup, along each axis.
+type WorkgroupSize [3]int
+
+// binding is implemented by [Buffer] and [Image] fields of a kernel.
+type binding interface {
+	declare(set, binding int, name string) string
+	attach(device RenderingDevice.Instance, changed func())
+	uniform(binding int) (RDUniform.Instance, error)
+	free()
+}
+
+// Pipeline is a compiled [Kernel], ready to be dispatched.
+type Pipeline struct {
+	device   RenderingDevice.Instance
+	kernel   Kernel
+	shader   RID.Shader
+	pipeline RID.ComputePipeline
+	bindings []binding
+	set      RID.UniformSet
+	changed  bool
+}
+
+// Source returns the GLSL source code for the kernel, the kernel's bindings are linked to the
+// resulting GLSL, so the kernel should not be reused with another pipeline.
+func Source(kernel Kernel, size WorkgroupSize) string {
+	source, _ := compile(kernel, size)
+	return source
+}
+
+func compile(kernel Kernel, size WorkgroupSize) (string, []binding) {
+	value := reflect.ValueOf(kernel)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("Compute: kernel %T must be a pointer to a struct", kernel))
+	}
+	for i, n := range size {
+		if n == 0 {
+			size[i] = 1
+		}
+	}
+	var w strings.Builder
+	fmt.Fprintf(&w, "#version 450\n\n")
+	fmt.Fprintf(&w, "// Code generated by graphics.gd/shaders DO NOT EDIT!\n\n")
+	fmt.Fprintf(&w, "layout(local_size_x = %d, local_size_y = %d, local_size_z = %d) in;\n\n", size[0], size[1], size[2])
+	var bindings []binding
+	value = value.Elem()
+	rtype := value.Type()
+	for i := range rtype.NumField() {
+		field := rtype.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		bind, ok := value.Field(i).Addr().Interface().(binding)
+		if !ok {
+			panic(fmt.Sprintf("Compute: field %s of %v must be a Compute.Buffer or a Compute.Image", field.Name, rtype))
+		}
+		name := String.ToSnakeCase(field.Name)
+		if tag := field.Tag.Get("gd"); tag != "" {
+			name = tag
+		}
+		fmt.Fprintf(&w, "%s\n", bind.declare(0, len(bindings), name))
+		bindings = append(bindings, bind)
+	}
+	fmt.Fprintln(&w)
+	var invocation Invocation
+	shaders.CompileMain(&w, &invocation, func() {
+		kernel.Compute(invocation)
+	})
+	return w.String(), bindings
+}
+
+// New compiles the kernel into a compute pipeline on the given device. The workgroup size
+// defaults to 1 along any axis that is zero.
+func New(device RenderingDevice.Instance, kernel Kernel, size WorkgroupSize) (*Pipeline, error) {
+	code, bindings := compile(kernel, size)
+	source := RDShaderSource.New()
+	source.SetLanguage(Rendering.ShaderLanguageGlsl)
+	source.SetSourceCompute(code)
+	spirv := device.ShaderCompileSpirvFromSource(source)
+	if err := spirv.CompileErrorCompute(); err != "" {
+		return nil, fmt.Errorf("Compute: failed to compile %T: %s\n\n%s", kernel, err, code)
+	}
+	p := &Pipeline{device: device, kernel: kernel, bindings: bindings, changed: true}
+	p.shader = device.ShaderCreateFromSpirv(spirv)
+	if !RID.Any(p.shader).IsValid() {
+		return nil, fmt.Errorf("Compute: failed to create shader for %T", kernel)
+	}
+	p.pipeline = device.ComputePipelineCreate(p.shader)
+	for _, bind := range bindings {
+		bind.attach(device, func() { p.changed = true })
+	}
+	return p, nil
+}
+
+// Dispatch runs the kernel on the given number of workgroups along each axis. All of the kernel's
+// bindings must have been uploaded, created or bound beforehand. On a local rendering device,
+// call [Pipeline.Wait] to wait for the results.
+func (p *Pipeline) Dispatch(x, y, z int) error {
+	if p.changed && len(p.bindings) > 0 {
+		if p.device.UniformSetIsValid(p.set) {
+			p.device.FreeRid(RID.Any(p.set))
+		}
+		var uniforms []RDUniform.Instance
+		for i, bind := range p.bindings {
+			uniform, err := bind.uniform(i)
+			if err != nil {
+				return err
+			}
+			uniforms = append(uniforms, uniform)
+		}
+		p.set = p.device.UniformSetCreate(uniforms, p.shader, 0)
+		p.changed = false
+	}
+	list := p.device.ComputeListBegin()
+	p.device.ComputeListBindComputePipeline(list, p.pipeline)
+	if len(p.bindings) > 0 {
+		p.device.ComputeListBindUniformSet(list, p.set, 0)
+	}
+	p.device.ComputeListDispatch(list, x, y, z)
+	p.device.ComputeListEnd()
+	return nil
+}
+
+// Wait submits the dispatched work to a local rendering device and blocks until it has finished.
+func (p *Pipeline) Wait() {
+	p.device.Submit()
+	p.device.Sync()
+}
+
+// Free releases the pipeline and all of the buffers and images it created.
+func (p *Pipeline) Free() {
+	for _, bind := range p.bindings {
+		bind.free()
+	}
+	if p.device.UniformSetIsValid(p.set) {
+		p.device.FreeRid(RID.Any(p.set))
+	}
+	p.device.FreeRid(RID.Any(p.pipeline))
+	p.device.FreeRid(RID.Any(p.shader))
+}
+
+var errUnbound = errors.New("binding has not been uploaded, created or bound")
@@ -0,0 +1,45 @@
+package Compute
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"graphics.gd/shaders/uint"
+	"graphics.gd/shaders/vec4"
+)
+
+var update = flag.Bool("update", false, "update the golden .glsl files in testdata")
+
+// Simulation is the kernel from the package documentation.
+type Simulation struct {
+	Positions  Buffer[vec4.XYZW]
+	Velocities Buffer[vec4.XYZW]
+}
+
+func (sim *Simulation) Compute(id Invocation) {
+	i := uint.X(id.GlobalID.X)
+	sim.Positions.Store(i, vec4.Add(sim.Positions.Load(i), sim.Velocities.Load(i)))
+}
+
+func TestSource(t *testing.T) {
+	code := Source(new(Simulation), WorkgroupSize{64, 1, 1})
+	path := filepath.Join("testdata", "simulation.glsl")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if code != string(expected) {
+		t.Errorf("%s does not match the compiled kernel (run go test -update if this is expected):\n%s", path, code)
+	}
+}
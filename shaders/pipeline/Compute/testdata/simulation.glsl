@@ -0,0 +1,12 @@
+#version 450
+
+// Code generated by graphics.gd/shaders DO NOT EDIT!
+
+layout(local_size_x = 64, local_size_y = 1, local_size_z = 1) in;
+
+layout(set = 0, binding = 0, std430) restrict buffer Buffer0 { vec4 data[]; } positions;
+layout(set = 0, binding = 1, std430) restrict buffer Buffer1 { vec4 data[]; } velocities;
+
+void main() {
+	positions.data[gl_GlobalInvocationID.x] = (positions.data[gl_GlobalInvocationID.x] + velocities.data[gl_GlobalInvocationID.x]);
+}
@@ -1,10 +1,10 @@
-// Package Fog provides a fog shader pipeline used for shading 3D objects.
+// Package Fog provides the fog shader pipeline, used to write the density, albedo and emission of
+// each froxel inside a FogVolume to the volumetric fog.
 package Fog
 
 import (
 	"reflect"
 
-	"graphics.gd/classdb/ShaderMaterial"
 	"graphics.gd/internal/gdclass"
 	"graphics.gd/shaders"
 	"graphics.gd/shaders/float"
@@ -12,7 +12,7 @@ import (
 )
 
 type Shader[T gdclass.Interface] struct {
-	ShaderMaterial.Extension[T]
+	shaders.Extension[T]
 }
 
 func (s *Shader[T]) OnCreate(value reflect.Value) {
@@ -22,21 +22,22 @@ func (s *Shader[T]) OnCreate(value reflect.Value) {
 func (*Shader[T]) ShaderType() string       { return "fog" }
 func (*Shader[T]) RenderMode() []RenderMode { return nil }
 func (*Shader[T]) Pipeline() [3]string {
-	return [3]string{"", "", "fog"}
+	return [3]string{"", "fog", ""}
 }
 
 func (*Shader[T]) Fragment(state struct{}) Fragment { return Fragment{} }
 func (*Shader[T]) Material(state Fragment) Material { return Material{} }
 func (*Shader[T]) Lighting(Material) struct{}       { return struct{}{} }
 
+// RenderMode of a fog shader, there are currently none.
 type RenderMode string
 
 type Fragment struct {
 	WorldPosition  vec3.XYZ `gd:"WORLD_POSITION"`  // Position of current froxel cell in world space.
 	ObjectPosition vec3.XYZ `gd:"OBJECT_POSITION"` // Position of the center of the current FogVolume in world space.
 	UVW            vec3.XYZ `gd:"UVW"`             // 3-dimensional UV, used to map a 3D texture to the current FogVolume.
-	Size           vec3.XYZ `gd:"SIZE"`            // Size of the current FogVolume when its shape has a size.
-	SDF            vec3.XYZ `gd:"SDF"`             // Signed distance field to the surface of the FogVolume. Negative if inside volume, positive otherwise.
+	Size           vec3.XYZ `gd:"SIZE"`            // Size of the current FogVolume when its shape has a size (this replaced EXTENTS, which was half the size).
+	SDF            float.X  `gd:"SDF"`             // Signed distance field to the surface of the FogVolume. Negative if inside volume, positive otherwise.
 }
 
 type Material struct {
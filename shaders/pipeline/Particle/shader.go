@@ -4,7 +4,6 @@ package Particle
 import (
 	"reflect"
 
-	"graphics.gd/classdb/ShaderMaterial"
 	"graphics.gd/internal/gdclass"
 	"graphics.gd/shaders"
 	"graphics.gd/shaders/bool"
@@ -26,7 +25,7 @@ which are then used by a CanvasItem or Spatial shader. They contain two processo
 Unlike other shader types, particle shaders keep the data that was output the previous frame. Therefore, particle shaders can be used for complex effects that take place over multiple frames.
 */
 type Shader[T gdclass.Interface] struct {
-	ShaderMaterial.Extension[T]
+	shaders.Extension[T]
 }
 
 func (s *Shader[T]) OnCreate(value reflect.Value) {
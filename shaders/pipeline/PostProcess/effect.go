@@ -0,0 +1,143 @@
+package PostProcess
+
+import (
+	"fmt"
+	"reflect"
+
+	"graphics.gd/classdb/CompositorEffect"
+	"graphics.gd/classdb/Engine"
+	"graphics.gd/classdb/RDSamplerState"
+	"graphics.gd/classdb/RDShaderSource"
+	"graphics.gd/classdb/RDUniform"
+	"graphics.gd/classdb/RenderData"
+	"graphics.gd/classdb/RenderSceneBuffersRD"
+	"graphics.gd/classdb/Rendering"
+	"graphics.gd/classdb/RenderingDevice"
+	"graphics.gd/classdb/RenderingServer"
+	"graphics.gd/classdb/UniformSetCacheRD"
+	"graphics.gd/internal/gdclass"
+	"graphics.gd/variant/Object"
+	"graphics.gd/variant/RID"
+	"graphics.gd/variant/Vector3"
+)
+
+// Effect is embedded into a [Pass] to run it as a CompositorEffect, after the transparent objects
+// of the scene have been rendered.
+type Effect[T gdclass.Interface] struct {
+	CompositorEffect.Extension[T]
+
+	pass     Pass
+	device   RenderingDevice.Instance
+	shader   RID.Shader
+	pipeline RID.ComputePipeline
+	sampler  RID.Sampler
+	err      error
+}
+
+func (e *Effect[T]) OnCreate(value reflect.Value) {
+	e.pass = value.Interface().(Pass)
+	effect := e.Super()
+	effect.SetEffectCallbackType(CompositorEffect.EffectCallbackTypePostTransparent)
+	effect.SetAccessResolvedColor(true)
+	effect.SetAccessResolvedDepth(true)
+}
+
+// compile the pass on the rendering device, only the first error is reported.
+func (e *Effect[T]) compile() error {
+	if e.err != nil || RID.Any(e.shader).IsValid() {
+		return e.err
+	}
+	e.device = RenderingServer.GetRenderingDevice()
+	code := Source(e.pass)
+	source := RDShaderSource.New()
+	source.SetLanguage(Rendering.ShaderLanguageGlsl)
+	source.SetSourceCompute(code)
+	spirv := e.device.ShaderCompileSpirvFromSource(source)
+	if err := spirv.CompileErrorCompute(); err != "" {
+		e.err = fmt.Errorf("PostProcess: failed to compile %T: %s\n\n%s", e.pass, err, code)
+		return e.err
+	}
+	e.shader = e.device.ShaderCreateFromSpirv(spirv)
+	if !RID.Any(e.shader).IsValid() {
+		e.err = fmt.Errorf("PostProcess: failed to create shader for %T", e.pass)
+		return e.err
+	}
+	e.pipeline = e.device.ComputePipelineCreate(e.shader)
+	state := RDSamplerState.New()
+	state.SetMinFilter(Rendering.SamplerFilterNearest)
+	state.SetMagFilter(Rendering.SamplerFilterNearest)
+	e.sampler = e.device.SamplerCreate(state)
+	return nil
+}
+
+// RenderCallback runs the pass over each view of the rendered scene, on the rendering thread.
+func (e *Effect[T]) RenderCallback(callback int, data RenderData.Instance) {
+	if CompositorEffect.EffectCallbackType(callback) != CompositorEffect.EffectCallbackTypePostTransparent {
+		return
+	}
+	buffers, ok := Object.As[RenderSceneBuffersRD.Instance](data.GetRenderSceneBuffers())
+	if !ok {
+		return
+	}
+	if e.err == nil {
+		if err := e.compile(); err != nil {
+			Engine.Raise(err)
+		}
+	}
+	if e.err != nil {
+		return
+	}
+	size := buffers.GetInternalSize()
+	if size.X == 0 || size.Y == 0 {
+		return
+	}
+	groupsX, groupsY := (int(size.X)+7)/8, (int(size.Y)+7)/8
+	// the pass writes to the color image in place, so ColorAt reads from a copy of it, which the
+	// scene buffers free when they are resized.
+	format := e.device.TextureGetFormat(buffers.GetColorTexture())
+	views := buffers.GetViewCount()
+	copied := buffers.CreateTexture("PostProcess", "color", format.Format(),
+		int(Rendering.TextureUsageSamplingBit|Rendering.TextureUsageCanCopyToBit),
+		Rendering.TextureSamples1, size, views, 1, true, false)
+	for view := range views {
+		if err := e.device.TextureCopy(buffers.GetColorLayer(view), copied, Vector3.Zero, Vector3.Zero,
+			Vector3.XYZ{X: float32(size.X), Y: float32(size.Y), Z: 1}, 0, 0, 0, view); err != nil {
+			Engine.Raise(err)
+			return
+		}
+		image := RDUniform.New()
+		image.SetUniformType(Rendering.UniformTypeImage)
+		image.SetBinding(0)
+		image.AddId(RID.Any(buffers.GetColorLayer(view)))
+		depth := RDUniform.New()
+		depth.SetUniformType(Rendering.UniformTypeSamplerWithTexture)
+		depth.SetBinding(1)
+		depth.AddId(RID.Any(e.sampler))
+		depth.AddId(RID.Any(buffers.GetDepthLayer(view)))
+		color := RDUniform.New()
+		color.SetUniformType(Rendering.UniformTypeSamplerWithTexture)
+		color.SetBinding(2)
+		color.AddId(RID.Any(e.sampler))
+		color.AddId(RID.Any(buffers.GetTextureSlice("PostProcess", "color", view, 0, 1, 1)))
+		set := UniformSetCacheRD.GetCache(e.shader, 0, []RDUniform.Instance{image, depth, color})
+		list := e.device.ComputeListBegin()
+		e.device.ComputeListBindComputePipeline(list, e.pipeline)
+		e.device.ComputeListBindUniformSet(list, set, 0)
+		e.device.ComputeListDispatch(list, groupsX, groupsY, 1)
+		e.device.ComputeListEnd()
+	}
+}
+
+// OnFree releases the compiled pass, on the rendering thread that created it.
+func (e *Effect[T]) OnFree() {
+	if !RID.Any(e.shader).IsValid() {
+		return
+	}
+	device, shader, pipeline, sampler := e.device, e.shader, e.pipeline, e.sampler
+	e.shader, e.pipeline, e.sampler = 0, 0, 0
+	RenderingServer.CallOnRenderThread(func() {
+		device.FreeRid(RID.Any(sampler))
+		device.FreeRid(RID.Any(pipeline))
+		device.FreeRid(RID.Any(shader))
+	})
+}
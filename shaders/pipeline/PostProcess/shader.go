@@ -0,0 +1,90 @@
+/*
+Package PostProcess provides a post-processing pipeline, for full screen effects written in Go that
+are run by a CompositorEffect after the 3D scene has been rendered.
+
+A pass is a struct with a Process method, which returns the new color of each pixel on the screen:
+
+	type Grayscale struct {
+		PostProcess.Effect[Grayscale]
+	}
+
+	func (Grayscale) Process(pixel PostProcess.Pixel) vec4.RGBA {
+		luma := rgb.Dot(rgb.New(pixel.Color.R, pixel.Color.G, pixel.Color.B), rgb.New(0.299, 0.587, 0.114))
+		return rgba.New(luma, luma, luma, pixel.Color.A)
+	}
+
+	classdb.Register[Grayscale]()
+
+The effect is then added to the compositor_effects of a Compositor, either in the editor or with
+[Compositor.Instance.SetCompositorEffects]. Compositor effects are only supported by the Forward+
+renderer.
+
+As with the other pipelines, Go branches only run when the pass is compiled, use [shaders.If]
+and [shaders.Loop] to branch and loop on the GPU.
+*/
+package PostProcess
+
+import (
+	"fmt"
+	"strings"
+
+	"graphics.gd/shaders"
+	"graphics.gd/shaders/bool"
+	"graphics.gd/shaders/float"
+	"graphics.gd/shaders/int"
+	"graphics.gd/shaders/internal/gpu"
+	"graphics.gd/shaders/ivec2"
+	"graphics.gd/shaders/vec2"
+	"graphics.gd/shaders/vec4"
+)
+
+// Pass is implemented by the struct embedding an [Effect], the Process method is run once for
+// each pixel on the screen.
+type Pass interface {
+	Process(Pixel) vec4.RGBA
+}
+
+// Pixel of the rendered scene.
+type Pixel struct {
+	Coord ivec2.XY  `gd:"COORD"` // Coordinate of the pixel, in pixels.
+	Size  ivec2.XY  `gd:"SIZE"`  // Size of the screen, in pixels.
+	UV    vec2.XY   `gd:"UV"`    // Coordinate of the center of the pixel, normalized to the range 0 to 1.
+	Color vec4.RGBA `gd:"COLOR"` // Color of the pixel, in linear color space.
+	Depth float.X   `gd:"DEPTH"` // Raw depth buffer value of the pixel, 1 at the near plane and 0 at the far plane.
+}
+
+// ColorAt returns the color of the pixel at the given coordinates, as it was before the pass ran.
+func (Pixel) ColorAt(coord ivec2.XY) vec4.RGBA {
+	return gpu.NewRGBAExpression(gpu.Fn("color_at", coord))
+}
+
+// DepthAt returns the raw depth buffer value of the pixel at the given coordinates.
+func (Pixel) DepthAt(coord ivec2.XY) float.X {
+	return gpu.NewFloatExpression(gpu.Fn("depth_at", coord))
+}
+
+// Source returns the GLSL compute shader source code for the pass.
+func Source(pass Pass) string {
+	var w strings.Builder
+	fmt.Fprintf(&w, "#version 450\n\n")
+	fmt.Fprintf(&w, "// Code generated by graphics.gd/shaders DO NOT EDIT!\n\n")
+	fmt.Fprintf(&w, "layout(local_size_x = 8, local_size_y = 8, local_size_z = 1) in;\n\n")
+	fmt.Fprintf(&w, "layout(rgba16f, set = 0, binding = 0) uniform image2D color_image;\n")
+	fmt.Fprintf(&w, "layout(set = 0, binding = 1) uniform sampler2D depth_texture;\n")
+	fmt.Fprintf(&w, "layout(set = 0, binding = 2) uniform sampler2D color_texture;\n\n")
+	fmt.Fprintf(&w, "float depth_at(ivec2 coord) { return texelFetch(depth_texture, coord, 0).r; }\n")
+	fmt.Fprintf(&w, "vec4 color_at(ivec2 coord) { return texelFetch(color_texture, coord, 0); }\n\n")
+	fmt.Fprintf(&w, "#define COORD ivec2(gl_GlobalInvocationID.xy)\n")
+	fmt.Fprintf(&w, "#define SIZE imageSize(color_image)\n")
+	fmt.Fprintf(&w, "#define UV ((vec2(COORD) + 0.5) / vec2(SIZE))\n")
+	fmt.Fprintf(&w, "#define COLOR imageLoad(color_image, COORD)\n")
+	fmt.Fprintf(&w, "#define DEPTH depth_at(COORD)\n\n")
+	var pixel Pixel
+	shaders.CompileMain(&w, &pixel, func() {
+		color := pass.Process(pixel)
+		shaders.If(bool.And(int.Lt(pixel.Coord.X, pixel.Size.X), int.Lt(pixel.Coord.Y, pixel.Size.Y)), func() {
+			gpu.Emit("PostProcess.Store", gpu.Eval{Value: gpu.Fn("imageStore", gpu.Identifier("color_image"), pixel.Coord, color)})
+		}, nil)
+	})
+	return w.String()
+}
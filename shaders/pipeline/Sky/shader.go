@@ -1,11 +1,11 @@
-// Package Sky provides a sky shader pipeline used for shading 3D objects.
+// Package Sky provides the sky shader pipeline, used to draw the background of a WorldEnvironment
+// and to update its radiance cubemap for reflections and ambient light.
 package Sky
 
 import (
 	"fmt"
 	"reflect"
 
-	"graphics.gd/classdb/ShaderMaterial"
 	"graphics.gd/internal/gdclass"
 	"graphics.gd/shaders"
 	"graphics.gd/shaders/bool"
@@ -18,7 +18,7 @@ import (
 )
 
 type Shader[T gdclass.Interface] struct {
-	ShaderMaterial.Extension[T]
+	shaders.Extension[T]
 }
 
 func (s *Shader[T]) OnCreate(value reflect.Value) {
@@ -41,6 +41,7 @@ const (
 	UseHalfResPass    RenderMode = "use_half_res_pass"    // Allows the shader to write to and access the half resolution pass.
 	UseQuarterResPass RenderMode = "use_quarter_res_pass" // Allows the shader to write to and access the quarter resolution pass.
 	DisableFog        RenderMode = "disable_fog"          // If used, fog will not affect the sky.
+	UseDebanding      RenderMode = "use_debanding"        // Applies debanding to the sky, to reduce banding in smooth gradients.
 )
 
 type Snapshot struct {
@@ -49,13 +50,16 @@ type Snapshot struct {
 	AtHalfResPass    bool.X                         `gd:"AT_HALF_RES_PASS"`    // True if the shader is being processed at half resolution pass.
 	AtQuarterResPass bool.X                         `gd:"AT_QUARTER_RES_PASS"` // True if the shader is being processed at quarter resolution pass.
 	AtCubemapPass    bool.X                         `gd:"AT_CUBEMAP_PASS"`     // True if the shader is being processed at cubemap pass.
-	EyeDirection     vec3.XYZ                       `gd:"EYE_DIRECTION"`       // Normalized direction of current pixel. Use this as your basic direction for procedural effects.
+	EyeDirection     vec3.XYZ                       `gd:"EYEDIR"`              // Normalized direction of current pixel. Use this as your basic direction for procedural effects.
 	ScreenUV         vec2.XY                        `gd:"SCREEN_UV"`           // Screen UV coordinate for current pixel. Used to map a texture to the full screen.
 	SkyCoords        vec2.XY                        `gd:"SKY_COORDS"`          // Sphere UV. Used to map a panorama texture to the sky.
 	HalfResColor     vec4.RGBA                      `gd:"HALF_RES_COLOR"`      // Color value of corresponding pixel from half resolution pass. Uses linear filter.
 	QuarterResColor  vec4.RGBA                      `gd:"QUARTER_RES_COLOR"`   // Color value of corresponding pixel from quarter resolution pass. Uses linear filter.
+	FragCoord        vec4.XYZW                      `gd:"FRAGCOORD"`           // Coordinate of pixel center in screen space, xy specifies the position in the window. Only available in the background pass.
 }
 
+// Lights returns the first four DirectionalLight3D nodes in the scene, check Enabled before
+// using the other properties of a light.
 func (Snapshot) Lights() [4]Light {
 	var lights [4]Light
 	for i := range 4 {
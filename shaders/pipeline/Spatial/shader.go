@@ -4,7 +4,6 @@ package Spatial
 import (
 	"reflect"
 
-	"graphics.gd/classdb/ShaderMaterial"
 	"graphics.gd/internal/gdclass"
 	"graphics.gd/shaders"
 	"graphics.gd/shaders/float"
@@ -26,13 +25,14 @@ Scattering, Transmission, Ambient Occlusion, Rim lighting etc). Users can option
 and light processor functions to affect how objects are drawn.
 */
 type Shader[T gdclass.Interface] struct {
-	ShaderMaterial.Extension[T]
+	shaders.Extension[T]
 }
 
 func (s *Shader[T]) OnCreate(value reflect.Value) {
 	shaders.CompileAny(value.Interface().(shaders.Any))
 }
 
+// GetPropertyList returns no properties, as the uniforms are listed by the ShaderMaterial.
 func (s *Shader[T]) GetPropertyList() []Object.PropertyInfo { return nil }
 
 func (*Shader[T]) ShaderType() string       { return "spatial" }
@@ -0,0 +1,64 @@
+package shaders
+
+import (
+	"reflect"
+
+	"graphics.gd/classdb/ShaderMaterial"
+	"graphics.gd/internal/gdclass"
+)
+
+// Extension of the ShaderMaterial class, embedded by the Shader type of each pipeline. Get and
+// Set leave the uniforms of T to the ShaderMaterial, see [GetProperty].
+type Extension[T gdclass.Interface] struct {
+	ShaderMaterial.Extension[T]
+}
+
+func (e *Extension[T]) Get(name string) (any, bool) {
+	return GetProperty[T](e.AsShaderMaterial(), name)
+}
+func (e *Extension[T]) Set(name string, value any) bool {
+	return SetProperty[T](e.AsShaderMaterial(), name, value)
+}
+
+// GetProperty implements the Get method of [Extension] for a shader of type T. The uniforms of
+// the shader are listed by the ShaderMaterial as shader_parameter/<name> properties, with the type,
+// hints and default value of their declaration, so that they are edited in the inspector and saved
+// into .tres and .tscn files like the parameters of any other ShaderMaterial. The fields of T are
+// not properties, so they must not shadow those of the ShaderMaterial, each uniform can also be
+// read by its own name.
+func GetProperty[T any](material ShaderMaterial.Instance, name string) (any, bool) {
+	if _, ok := uniform(reflect.TypeFor[*T](), name); ok {
+		return material.GetShaderParameter(name), true
+	}
+	return nil, false
+}
+
+// SetProperty implements the Set method of [Extension] for a shader of type T, see [GetProperty].
+// The compiled shader is kept when a saved material is loaded, such that the Go code is always the
+// source of truth for the shader.
+func SetProperty[T any](material ShaderMaterial.Instance, name string, value any) bool {
+	cache.Lock()
+	_, compiled := cache.shaders[reflect.TypeFor[*T]()]
+	cache.Unlock()
+	if name == "shader" && compiled {
+		return true
+	}
+	if _, ok := uniform(reflect.TypeFor[*T](), name); ok {
+		material.SetShaderParameter(name, value)
+		return true
+	}
+	return false
+}
+
+// uniform returns the declaration of the material uniform with the given name, for the compiled
+// shader of the given type.
+func uniform(rtype reflect.Type, name string) (declaration, bool) {
+	cache.Lock()
+	defer cache.Unlock()
+	for _, decl := range cache.shaders[rtype].uniforms {
+		if decl.name == name && decl.qualifier == "" {
+			return decl, true
+		}
+	}
+	return declaration{}, false
+}
@@ -43,8 +43,26 @@ Each sub-package provides GPU-specific shader types that can be used within a sh
 Keep in mind that the Go code is compiled to run on the GPU, so non-GPU values, function
 calls or branches will only take affect during compilation and not when rendering.
 
-All for loops will be unrolled. The shaders package does not currently support non-constant
-loops.
+Go for loops will be unrolled. To branch or loop on the GPU, use [If] and [Loop], together with
+[Var] and [Assign] to carry values out of the block. [Break] and [Continue] control the innermost
+[Loop] and [Discard] drops the current pixel in the Material stage.
+
+	steps := shaders.Var(int.New(0))
+	shaders.Loop(shader.MaxSteps, func(i int.X) {
+		shaders.If(float.Lt(distance(i), 0.001), shaders.Break, nil)
+		shaders.Assign(&steps, int.Add(steps, 1))
+	})
+
+Go functions called by a pipeline are inlined into the shader at each call site, wrap them with
+[Func] to compile them into a GLSL function instead, so that they are only emitted once.
+
+To pass custom data between stages, embed the stage struct of the pipeline (such as
+Spatial.Fragment) into a struct of your own and add fields to it, these are declared as GLSL
+varyings, which can be tagged as flat or smooth, ie. `gd:"sway,flat"`.
+
+The shader is compiled once for each Go type and shared by all of its instances, so the pipeline
+methods, render modes and uniform hints must not depend on the state of an instance, use uniforms
+for anything that varies between instances.
 
 # Uniforms
 
@@ -63,6 +81,16 @@ RenderingServer/GeometryInstance3D packages.
 	var shader = new(MyShader)
 	shaders.Compile(&shader)
 	shaders.Set(&shader.MyUniform, Vector2.New(1, 2))
+
+Uniforms wrapped inside the [Global] generic type refer to global shader parameters. Hints and
+default values are provided by a Uniforms method on the shader, see [Uniform]. Material uniforms
+are edited in the inspector and saved like the parameters of any other ShaderMaterial.
+
+# Testing
+
+[Evaluate] runs the Material and Lighting stages of a shader on the CPU, for a single pixel, so
+that shaders can be tested without a GPU. [Source] returns the generated shader code without the
+engine, 'gd generate shaders' writes it to a .gdshader file for each registered shader.
 */
 package shaders
 
@@ -71,16 +99,17 @@ import (
 	"io"
 	"reflect"
 	"strings"
+	"sync"
 
 	"graphics.gd/classdb/Engine"
 	"graphics.gd/classdb/Shader"
 	"graphics.gd/classdb/ShaderMaterial"
 	gd "graphics.gd/internal"
 	"graphics.gd/internal/gdclass"
+	"graphics.gd/internal/gdextension"
+	"graphics.gd/internal/pointers"
 	"graphics.gd/shaders/internal/gpu"
 	dsl "graphics.gd/shaders/internal/gpu"
-	"graphics.gd/shaders/vec2"
-	"graphics.gd/variant/String"
 )
 
 // Globals are available everywhere, including custom functions.
@@ -140,16 +169,87 @@ func Compile[V, F, M, L comparable, RM ~string](prog Program[V, F, M, L, RM]) {
 }
 
 func CompileAny(val Any) {
-	rtype := reflect.TypeOf(val)
+	v, f, m, l := stageTypes(val)
+	compile(val, v, f, m, l)
+}
+
+// stageTypes returns the input types of each pipeline method of the program, along with the
+// result type of the Lighting stage.
+func stageTypes(prog Any) (v, f, m, l reflect.Type) {
+	rtype := reflect.TypeOf(prog)
 	fragment, _ := rtype.MethodByName("Fragment")
 	material, _ := rtype.MethodByName("Material")
 	lighting, _ := rtype.MethodByName("Lighting")
-	compile(val, fragment.Type.In(1), material.Type.In(1), lighting.Type.In(1), lighting.Type.Out(0))
+	return fragment.Type.In(1), material.Type.In(1), lighting.Type.In(1), lighting.Type.Out(0)
+}
+
+// Source returns the Godot shading language source code for the program, without requiring the
+// engine, so that it can be inspected, diffed or written to a .gdshader file. The program's
+// uniforms are linked to the resulting source.
+func Source(prog Any) (code string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("shaders.Source: %T: %v", prog, r)
+		}
+	}()
+	v, f, m, l := stageTypes(prog)
+	code, _ = source(prog, v, f, m, l)
+	return code, nil
+}
+
+// compiled shader of a Go shader type, shared by each of its instances, which only carry their
+// own uniform values.
+type compiled struct {
+	shader   Shader.Instance
+	uniforms []declaration
+}
+
+var cache struct {
+	sync.Mutex
+	shaders map[reflect.Type]compiled
 }
 
+// compile the program into a Shader resource, the pipeline is only recorded for the first
+// instance of each Go type, the rest link their uniforms and reuse the cached Shader.
 func compile(prog Any, v, f, m, l reflect.Type) {
-	super := prog.AsShaderMaterial()
-	shader := Shader.New()
+	cache.Lock()
+	defer cache.Unlock()
+	rtype := reflect.TypeOf(prog)
+	cached, ok := cache.shaders[rtype]
+	if ok {
+		compileUniforms(io.Discard, prog)
+	} else {
+		var code string
+		code, cached.uniforms = source(prog, v, f, m, l)
+		cached.shader = Shader.New()
+		cached.shader.SetCode(code)
+		cached.shader.AsRefCounted()[0].Reference()
+		pointers.Pin(cached.shader.AsObject()[0])
+		if cache.shaders == nil {
+			cache.shaders = make(map[reflect.Type]compiled)
+			gd.RegisterCleanup(releaseShaders)
+		}
+		cache.shaders[rtype] = cached
+	}
+	prog.AsShaderMaterial().SetShader(cached.shader)
+}
+
+// releaseShaders releases the cached Shader resources, when the engine shuts down.
+func releaseShaders() {
+	cache.Lock()
+	defer cache.Unlock()
+	for _, cached := range cache.shaders {
+		object := cached.shader.AsObject()[0]
+		raw := pointers.Get(object)
+		if cached.shader.AsRefCounted()[0].Unreference() {
+			gdextension.Host.Objects.Unsafe.Free(gdextension.Object(raw[0]))
+		}
+		pointers.End(object)
+	}
+	cache.shaders = nil
+}
+
+func source(prog Any, v, f, m, l reflect.Type) (code string, uniforms []declaration) {
 	writer := strings.Builder{}
 	fmt.Fprintf(&writer, "// Code generated by graphics.gd/shaders DO NOT EDIT!\n")
 	fmt.Fprintf(&writer, "shader_type %s;\n\n", prog.ShaderType())
@@ -178,174 +278,130 @@ func compile(prog Any, v, f, m, l reflect.Type) {
 	var material = reflect.New(m).Elem()
 	linkup(material.Addr().Interface())
 
-	compileUniforms(&writer, prog)
-	if frag := rvalue.MethodByName("Fragment").Call([]reflect.Value{vertices}); !frag[0].IsZero() && pipeline[0] != "" {
-		compileFunction(&writer, frag[0].Interface(), pipeline[0])
-	}
-	if matl := rvalue.MethodByName("Material").Call([]reflect.Value{fragment}); !matl[0].IsZero() && pipeline[1] != "" {
-		compileFunction(&writer, matl[0].Interface(), pipeline[1])
+	uniforms = compileUniforms(&writer, prog)
+	compileVaryings(&writer, prog, varyings(f, m))
+	var stages strings.Builder
+	functions := gpu.Definitions(func() {
+		compileStage(&stages, rvalue.MethodByName("Fragment"), vertices, pipeline[0], false)
+		compileStage(&stages, rvalue.MethodByName("Material"), fragment, pipeline[1], true)
+		compileStage(&stages, rvalue.MethodByName("Lighting"), material, pipeline[2], false)
+	})
+	for _, fn := range functions {
+		compileDefinition(&writer, fn)
 	}
-	if lght := rvalue.MethodByName("Lighting").Call([]reflect.Value{material}); !lght[0].IsZero() && pipeline[2] != "" {
-		compileFunction(&writer, lght[0].Interface(), pipeline[2])
-	}
-	shader.SetCode(writer.String())
-	super.SetShader(shader)
+	writer.WriteString(stages.String())
+	return writer.String(), uniforms
 }
 
 func linkup(in any) {
 	value := reflect.ValueOf(in).Elem()
 	rtype := value.Type()
 	for i := range rtype.NumField() {
+		if v, ok := custom(rtype, rtype.Field(i)); ok {
+			identify(value.Field(i), dsl.Identifier(v.name))
+			continue
+		}
 		if value.Field(i).Kind() == reflect.Struct && rtype.Field(i).IsExported() {
 			linkup(value.Field(i).Addr().Interface())
 		}
 		if tag := rtype.Field(i).Tag.Get("gd"); tag != "" {
-			field := value.Field(i)
-			switch ptr := field.Addr().Interface().(type) {
-			case *vec2.XY:
-				dsl.Set(&ptr.X, dsl.Identifier(tag+".x"))
-				dsl.Set(&ptr.Y, dsl.Identifier(tag+".y"))
-			}
-			dsl.Set(value.Field(i).Addr().Interface().(dsl.Pointer), dsl.Identifier(tag))
+			identify(value.Field(i), dsl.Identifier(tag))
 		}
 	}
 }
 
-func compileUniforms(w io.Writer, prog Any) {
-	value := reflect.ValueOf(prog).Elem()
-	rtype := value.Type()
-	for i := range rtype.NumField() {
-		field := rtype.Field(i)
-		if field.Name == "Shader" {
-			continue
-		}
-		name := String.ToSnakeCase(field.Name)
-		options := ""
-		if tag := field.Tag.Get("gd"); tag != "" {
-			tag, options, _ = strings.Cut(tag, ",")
-			if tag != "" {
-				name = tag
-			}
-		}
-		fmt.Fprintf(w, "uniform %s %s", glslTypeFor(field.Type), name)
-		dsl.Set(value.Field(i).Addr().Interface().(dsl.Pointer), gpu.Uniform(name, prog))
-		if options != "" {
-			fmt.Fprintf(w, ": ")
-			var first bool = true
-			for option := range strings.SplitSeq(options, ",") {
-				if first {
-					first = false
-				} else {
-					fmt.Fprintf(w, ",")
-				}
-				fmt.Fprintf(w, " %s", option)
-			}
-		}
-		fmt.Fprintf(w, ";\n")
+// compileStage calls the pipeline method with the given input, recording any statements, and
+// then compiles the result into the named GLSL function.
+func compileStage(w io.Writer, method reflect.Value, input reflect.Value, name string, discard bool) {
+	var result reflect.Value
+	statements := gpu.Record(discard, func() {
+		result = method.Call([]reflect.Value{input})[0]
+	})
+	if name == "" || (result.IsZero() && len(statements) == 0) {
+		return
 	}
-	fmt.Fprintln(w)
+	compileFunction(w, result.Interface(), name, statements)
 }
 
-func glslTypeFor(t reflect.Type) string {
-	switch {
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Bool]()):
-		return "bool"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec2b]()):
-		return "bvec2"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec3b]()):
-		return "bvec3"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec4b]()):
-		return "bvec4"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Float]()):
-		return "float"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Int]()):
-		return "int"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec2i]()):
-		return "ivec2"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec3i]()):
-		return "ivec3"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec4i]()):
-		return "ivec4"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Mat2]()):
-		return "mat2"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Mat3]()):
-		return "mat3"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Mat4]()):
-		return "mat4"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec2]()):
-		return "vec2"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec3]()):
-		return "vec3"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec4]()):
-		return "vec4"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.RGB]()):
-		return "vec3"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.RGBA]()):
-		return "vec4"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Uint]()):
-		return "uint"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec2u]()):
-		return "uvec2"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec3u]()):
-		return "uvec3"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec4u]()):
-		return "uvec4"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec2]()):
-		return "vec2"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec3]()):
-		return "vec3"
-	case t.ConvertibleTo(reflect.TypeFor[gpu.Vec4]()):
-		return "vec4"
-	case t.Implements(reflect.TypeFor[gpu.IsSampler2D]()):
-		elem := gpu.SamplerType(reflect.Zero(t).Interface().(gpu.IsSampler2D))
-		switch {
-		case elem.ConvertibleTo(reflect.TypeFor[gpu.Vec4]()):
-			return "sampler2D"
-		case elem.ConvertibleTo(reflect.TypeFor[gpu.Vec4i]()):
-			return "isampler2D"
-		case elem.ConvertibleTo(reflect.TypeFor[gpu.Vec4u]()):
-			return "usampler2D"
-		}
-	case t.Implements(reflect.TypeFor[gpu.IsSampler3D]()):
-		elem := gpu.SamplerType(reflect.Zero(t).Interface().(gpu.IsSampler3D))
-		switch {
-		case elem.ConvertibleTo(reflect.TypeFor[gpu.Vec4]()):
-			return "sampler3D"
-		case elem.ConvertibleTo(reflect.TypeFor[gpu.Vec4i]()):
-			return "isampler3D"
-		case elem.ConvertibleTo(reflect.TypeFor[gpu.Vec4u]()):
-			return "usampler3D"
+func compileFunction(w io.Writer, data any, name string, statements []gpu.Statement) {
+	fmt.Fprintf(w, "void %s() {\n", name)
+	compileStatements(w, statements, 1)
+	value := reflect.ValueOf(data)
+	stageFields(value.Type(), func(name string, index []int) {
+		field := value.FieldByIndex(index)
+		if !field.IsZero() {
+			fmt.Fprintf(w, "\t%s = ", name)
+			compileExpression(w, field.Interface().(dsl.Evaluator))
+			fmt.Fprintf(w, ";\n")
 		}
-	case t.Implements(reflect.TypeFor[gpu.IsArraySampler2D]()):
-		elem := gpu.SamplerType(reflect.Zero(t).Interface().(gpu.IsArraySampler2D))
-		switch {
-		case elem.ConvertibleTo(reflect.TypeFor[gpu.Vec4]()):
-			return "sampler2DArray"
-		case elem.ConvertibleTo(reflect.TypeFor[gpu.Vec4i]()):
-			return "isampler2DArray"
-		case elem.ConvertibleTo(reflect.TypeFor[gpu.Vec4u]()):
-			return "usampler2DArray"
+	})
+	fmt.Fprintf(w, "}\n")
+}
+
+// compileDefinition compiles a user-defined function, see [Func].
+func compileDefinition(w io.Writer, fn gpu.Function) {
+	fmt.Fprintf(w, "%s %s(", gpu.TypeName(fn.Result), fn.Name)
+	for i, param := range fn.Params {
+		if i > 0 {
+			fmt.Fprintf(w, ", ")
 		}
-	case t.Implements(reflect.TypeFor[gpu.IsCubeSampler]()):
-		return "samplerCube"
+		fmt.Fprintf(w, "%s %s", gpu.TypeName(param.Type), param.Name)
 	}
-	panic(fmt.Sprintf("unsupported GPU type %s", t))
+	fmt.Fprintf(w, ") {\n")
+	compileStatements(w, fn.Body, 1)
+	fmt.Fprintf(w, "\treturn ")
+	compileExpression(w, fn.Return)
+	fmt.Fprintf(w, ";\n}\n\n")
 }
 
-func compileFunction(w io.Writer, data any, name string) {
-	fmt.Fprintf(w, "void %s() {\n", name)
-	value := reflect.ValueOf(data)
-	rtype := value.Type()
-	for i := range rtype.NumField() {
-		field := rtype.Field(i)
-		expr, ok := value.Field(i).Interface().(dsl.Evaluator)
-		if ok && !value.Field(i).IsZero() {
-			fmt.Fprintf(w, "\t%s = ", field.Tag.Get("gd"))
-			compileExpression(w, expr)
+func compileStatements(w io.Writer, statements []gpu.Statement, depth int) {
+	indent := strings.Repeat("\t", depth)
+	for _, stmt := range statements {
+		switch stmt := stmt.(type) {
+		case gpu.Declare:
+			fmt.Fprintf(w, "%s%s %s = ", indent, gpu.TypeName(stmt.Type), stmt.Name)
+			compileExpression(w, stmt.Value)
+			fmt.Fprintf(w, ";\n")
+		case gpu.Assign:
+			fmt.Fprintf(w, "%s%s = ", indent, stmt.Name)
+			compileExpression(w, stmt.Value)
 			fmt.Fprintf(w, ";\n")
+		case gpu.Store:
+			fmt.Fprintf(w, "%s", indent)
+			compileExpression(w, stmt.Target)
+			fmt.Fprintf(w, " = ")
+			compileExpression(w, stmt.Value)
+			fmt.Fprintf(w, ";\n")
+		case gpu.Eval:
+			fmt.Fprintf(w, "%s", indent)
+			compileExpression(w, stmt.Value)
+			fmt.Fprintf(w, ";\n")
+		case gpu.If:
+			fmt.Fprintf(w, "%sif (", indent)
+			compileExpression(w, stmt.Cond)
+			fmt.Fprintf(w, ") {\n")
+			compileStatements(w, stmt.Then, depth+1)
+			if len(stmt.Else) > 0 {
+				fmt.Fprintf(w, "%s} else {\n", indent)
+				compileStatements(w, stmt.Else, depth+1)
+			}
+			fmt.Fprintf(w, "%s}\n", indent)
+		case gpu.Loop:
+			fmt.Fprintf(w, "%sfor (int %s = 0; %s < ", indent, stmt.Index, stmt.Index)
+			compileExpression(w, stmt.Count)
+			fmt.Fprintf(w, "; %s++) {\n", stmt.Index)
+			compileStatements(w, stmt.Body, depth+1)
+			fmt.Fprintf(w, "%s}\n", indent)
+		case gpu.Break:
+			fmt.Fprintf(w, "%sbreak;\n", indent)
+		case gpu.Continue:
+			fmt.Fprintf(w, "%scontinue;\n", indent)
+		case gpu.Discard:
+			fmt.Fprintf(w, "%sdiscard;\n", indent)
+		default:
+			panic(fmt.Sprintf("unsupported statement type %T", stmt))
 		}
 	}
-	fmt.Fprintf(w, "}\n")
 }
 
 func compileExpression(w io.Writer, expression dsl.Evaluator) {
@@ -421,8 +477,12 @@ func compileExpression(w io.Writer, expression dsl.Evaluator) {
 		switch value := expression.(type) {
 		case dsl.Operation:
 			fmt.Fprintf(w, "(")
-			compileExpression(w, value.A)
-			fmt.Fprintf(w, " %s ", value.Op)
+			if value.A != nil {
+				compileExpression(w, value.A)
+				fmt.Fprintf(w, " %s ", value.Op)
+			} else {
+				fmt.Fprintf(w, "%s", value.Op) // unary
+			}
 			compileExpression(w, value.B)
 			fmt.Fprintf(w, ")")
 		case dsl.Identifier:
@@ -437,6 +497,11 @@ func compileExpression(w io.Writer, expression dsl.Evaluator) {
 			fmt.Fprintf(w, ")")
 		case dsl.FunctionCall:
 			compileCall(w, string(value.Name), value.Args...)
+		case dsl.Index:
+			compileExpression(w, value.Array)
+			fmt.Fprintf(w, "[")
+			compileExpression(w, value.Index)
+			fmt.Fprintf(w, "]")
 		default:
 			panic(fmt.Sprintf("unsupported expression type %T", expression))
 		}
@@ -0,0 +1,293 @@
+package shaders_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"graphics.gd/shaders"
+	"graphics.gd/shaders/bool"
+	"graphics.gd/shaders/float"
+	"graphics.gd/shaders/int"
+	"graphics.gd/shaders/ivec2"
+	"graphics.gd/shaders/pipeline/CanvasItem"
+	"graphics.gd/shaders/pipeline/Fog"
+	"graphics.gd/shaders/pipeline/PostProcess"
+	"graphics.gd/shaders/pipeline/Sky"
+	"graphics.gd/shaders/pipeline/Spatial"
+	"graphics.gd/shaders/rgb"
+	"graphics.gd/shaders/rgba"
+	"graphics.gd/shaders/texture"
+	"graphics.gd/shaders/vec2"
+	"graphics.gd/shaders/vec3"
+	"graphics.gd/shaders/vec4"
+)
+
+var update = flag.Bool("update", false, "update the golden .gdshader files in testdata")
+
+type Solid struct {
+	CanvasItem.Shader[Solid]
+}
+
+func (Solid) Material(fragment CanvasItem.Fragment) CanvasItem.Material {
+	return CanvasItem.Material{
+		Color: rgba.New(1.0, 0.5, 0.0, 1.0),
+	}
+}
+
+var ring = shaders.Func(func(uv vec2.XY, radius float.X) float.X {
+	return float.Abs(float.Sub(vec2.Distance(uv, vec2.New(0.5, 0.5)), radius))
+})
+
+type Ring struct {
+	CanvasItem.Shader[Ring]
+
+	Tint   vec4.RGBA                    `gd:"tint"`
+	Radius float.X                      `gd:"radius"`
+	Mask   texture.Sampler2D[vec4.RGBA] `gd:"mask"`
+}
+
+func (r *Ring) Uniforms() []shaders.Uniform {
+	return []shaders.Uniform{
+		shaders.Hint(&r.Tint, shaders.SourceColor, shaders.Default(rgba.New(1.0, 1.0, 1.0, 1.0))),
+		shaders.Hint(&r.Radius, shaders.HintRange(0, 0.5, 0.01)),
+	}
+}
+
+func (r Ring) Material(fragment CanvasItem.Fragment) CanvasItem.Material {
+	color := shaders.Var(r.Tint)
+	mask := shaders.Var(r.Mask.Sample(fragment.UV))
+	shaders.If(float.Lt(mask.A, 0.5), shaders.Discard, nil)
+	shaders.If(float.Gt(ring(fragment.UV, r.Radius), 0.05), func() {
+		shaders.Assign(&color, rgba.New(0.0, 0.0, 0.0, 0.0))
+	}, nil)
+	return CanvasItem.Material{
+		Color: color,
+	}
+}
+
+type Wave struct {
+	Spatial.Shader[Wave]
+}
+
+func (Wave) Fragment(vertex Spatial.Vertex) Spatial.Fragment {
+	return Spatial.Fragment{
+		VertexPosition: vec3.Add(vertex.Position, vec3.New(0.0, float.Sin(float.Add(vertex.Position.X, shaders.Time)), 0.0)),
+		UV:             vertex.UV,
+	}
+}
+
+func (Wave) Material(fragment Spatial.Fragment) Spatial.Material {
+	return Spatial.Material{
+		Albedo:    rgb.New(fragment.UV.X, fragment.UV.Y, 1.0),
+		Roughness: float.New(0.25),
+	}
+}
+
+type Foliage struct {
+	Spatial.Shader[Foliage]
+}
+
+type FoliageFragment struct {
+	Spatial.Fragment
+
+	Sway  float.X
+	Layer int.X    `gd:"layer"`
+	Tint  vec3.RGB `gd:",flat"`
+}
+
+func (Foliage) Fragment(vertex Spatial.Vertex) FoliageFragment {
+	return FoliageFragment{
+		Fragment: Spatial.Fragment{
+			VertexPosition: vertex.Position,
+		},
+		Sway:  float.Sin(shaders.Time),
+		Layer: vertex.InstanceID,
+		Tint:  rgb.New(0.2, 0.8, 0.1),
+	}
+}
+
+func (Foliage) Material(fragment FoliageFragment) Spatial.Material {
+	return Spatial.Material{
+		Albedo: rgb.Mul(fragment.Tint, fragment.Sway),
+	}
+}
+
+type Mist struct {
+	Fog.Shader[Mist]
+}
+
+func (Mist) Material(fragment Fog.Fragment) Fog.Material {
+	return Fog.Material{
+		Albedo:  rgb.New(0.8, 0.8, 0.9),
+		Density: float.Clamp(float.Mul(fragment.SDF, -1.0), 0.0, 1.0),
+	}
+}
+
+// Volume reads each of the built-ins of the fog pipeline.
+type Volume struct {
+	Fog.Shader[Volume]
+}
+
+func (Volume) Material(fragment Fog.Fragment) Fog.Material {
+	inside := float.Clamp(float.Mul(fragment.SDF, -1.0), 0.0, 1.0)
+	falloff := float.Div(vec3.Distance(fragment.WorldPosition, fragment.ObjectPosition), vec3.Length(fragment.Size))
+	return Fog.Material{
+		Albedo:   rgb.New(fragment.UVW.X, fragment.UVW.Y, fragment.UVW.Z),
+		Density:  float.Mul(inside, float.Sub(1.0, falloff)),
+		Emission: rgb.New(0.1, 0.0, 0.0),
+	}
+}
+
+type Gradient struct {
+	Sky.Shader[Gradient]
+}
+
+func (Gradient) Lighting(snapshot Sky.Snapshot) Sky.Lighting {
+	return Sky.Lighting{
+		Color: rgb.Mul(rgb.New(0.2, 0.4, 0.9), float.Max(snapshot.EyeDirection.Y, 0.0)),
+	}
+}
+
+// Sunset reads each of the built-ins of the sky pipeline, with its render modes.
+type Sunset struct {
+	Sky.Shader[Sunset]
+}
+
+func (Sunset) RenderMode() []Sky.RenderMode {
+	return []Sky.RenderMode{Sky.UseHalfResPass, Sky.UseQuarterResPass, Sky.UseDebanding}
+}
+
+func (Sunset) Lighting(snapshot Sky.Snapshot) Sky.Lighting {
+	sun := snapshot.Lights()[0]
+	color := shaders.Var(rgb.New(snapshot.SkyCoords.X, snapshot.SkyCoords.Y, snapshot.ScreenUV.Y))
+	shaders.If(bool.And(snapshot.AtHalfResPass, sun.Enabled), func() {
+		glow := float.Max(vec3.Dot(sun.Direction, snapshot.EyeDirection), 0.0)
+		shaders.Assign(&color, rgb.Mul(sun.Color, float.Mul(sun.Energy, float.Div(glow, float.Add(sun.Size, 1.0)))))
+	}, nil)
+	shaders.If(snapshot.AtQuarterResPass, func() {
+		shaders.Assign(&color, rgb.New(snapshot.HalfResColor.R, snapshot.HalfResColor.G, snapshot.HalfResColor.B))
+	}, nil)
+	shaders.If(bool.Not(bool.Or(snapshot.AtCubemapPass, bool.Or(snapshot.AtHalfResPass, snapshot.AtQuarterResPass))), func() {
+		radiance := shaders.Var(snapshot.Radiance.Sample(snapshot.EyeDirection))
+		quarter := snapshot.QuarterResColor
+		shaders.Assign(&color, rgb.Add(rgb.New(radiance.R, radiance.G, radiance.B), rgb.New(quarter.R, quarter.G, quarter.B)))
+	}, nil)
+	return Sky.Lighting{
+		Color: color,
+		Alpha: float.New(1.0),
+		Fog:   rgba.New(0.0, 0.0, 0.0, float.Div(snapshot.FragCoord.Y, float.Add(vec3.Length(snapshot.CameraPosition), 1.0))),
+	}
+}
+
+type Grayscale struct {
+	PostProcess.Effect[Grayscale]
+}
+
+func (Grayscale) Process(pixel PostProcess.Pixel) vec4.RGBA {
+	luma := rgb.Dot(rgb.New(pixel.Color.R, pixel.Color.G, pixel.Color.B), rgb.New(0.299, 0.587, 0.114))
+	return rgba.New(luma, luma, luma, pixel.Color.A)
+}
+
+// Shift moves the screen one pixel to the right, the pixel to the left must be read before the
+// pass wrote to it.
+type Shift struct {
+	PostProcess.Effect[Shift]
+}
+
+func (Shift) Process(pixel PostProcess.Pixel) vec4.RGBA {
+	return pixel.ColorAt(ivec2.Sub(pixel.Coord, ivec2.New(1, 0)))
+}
+
+// Snapshot derives a value from a variable before assigning to it, the derived value must not see
+// the assignment.
+type Snapshot struct {
+	CanvasItem.Shader[Snapshot]
+}
+
+func (Snapshot) Material(fragment CanvasItem.Fragment) CanvasItem.Material {
+	x := shaders.Var(float.New(1.0))
+	y := float.Add(x, 1.0)
+	shaders.Assign(&x, float.New(5.0))
+	return CanvasItem.Material{
+		Color: rgba.New(float.Div(x, 10.0), float.Div(y, 10.0), 0.0, 1.0),
+	}
+}
+
+func TestSource(t *testing.T) {
+	for name, prog := range map[string]shaders.Any{
+		"solid":    new(Solid),
+		"ring":     new(Ring),
+		"wave":     new(Wave),
+		"foliage":  new(Foliage),
+		"mist":     new(Mist),
+		"sky":      new(Gradient),
+		"sunset":   new(Sunset),
+		"volume":   new(Volume),
+		"snapshot": new(Snapshot),
+	} {
+		t.Run(name, func(t *testing.T) {
+			code, err := shaders.Source(prog)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden(t, filepath.Join("testdata", name+".gdshader"), code)
+		})
+	}
+	t.Run("grayscale", func(t *testing.T) {
+		golden(t, filepath.Join("testdata", "grayscale.glsl"), PostProcess.Source(new(Grayscale)))
+	})
+	t.Run("shift", func(t *testing.T) {
+		golden(t, filepath.Join("testdata", "shift.glsl"), PostProcess.Source(new(Shift)))
+	})
+}
+
+type LegacyHints struct {
+	CanvasItem.Shader[LegacyHints]
+
+	Tint   vec4.RGBA                    `gd:"tint,source_color"`
+	Radius float.X                      `gd:"radius,hint_range(0, 0.5, 0.01)"`
+	Screen texture.Sampler2D[vec4.RGBA] `gd:"screen,screen_texture,filter_linear"`
+}
+
+func (l LegacyHints) Material(fragment CanvasItem.Fragment) CanvasItem.Material {
+	return CanvasItem.Material{
+		Color: l.Screen.Sample(fragment.UV),
+	}
+}
+
+func TestLegacyHints(t *testing.T) {
+	code, err := shaders.Source(new(LegacyHints))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uniform := range []string{
+		"uniform vec4 tint : source_color;\n",
+		"uniform float radius : hint_range(0, 0.5, 0.01);\n",
+		"uniform sampler2D screen : hint_screen_texture, filter_linear;\n",
+	} {
+		if !strings.Contains(code, uniform) {
+			t.Errorf("missing %q in:\n%s", uniform, code)
+		}
+	}
+}
+
+// golden compares the code to the golden file, or updates it when go test is run with -update.
+func golden(t *testing.T, path, code string) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if code != string(expected) {
+		t.Errorf("%s does not match the compiled shader (run go test -update if this is expected):\n%s", path, code)
+	}
+}
@@ -0,0 +1,24 @@
+#version 450
+
+// Code generated by graphics.gd/shaders DO NOT EDIT!
+
+layout(local_size_x = 8, local_size_y = 8, local_size_z = 1) in;
+
+layout(rgba16f, set = 0, binding = 0) uniform image2D color_image;
+layout(set = 0, binding = 1) uniform sampler2D depth_texture;
+layout(set = 0, binding = 2) uniform sampler2D color_texture;
+
+float depth_at(ivec2 coord) { return texelFetch(depth_texture, coord, 0).r; }
+vec4 color_at(ivec2 coord) { return texelFetch(color_texture, coord, 0); }
+
+#define COORD ivec2(gl_GlobalInvocationID.xy)
+#define SIZE imageSize(color_image)
+#define UV ((vec2(COORD) + 0.5) / vec2(SIZE))
+#define COLOR imageLoad(color_image, COORD)
+#define DEPTH depth_at(COORD)
+
+void main() {
+	if (((COORD.x < SIZE.x) && (COORD.y < SIZE.y))) {
+		imageStore(color_image, COORD, vec4(dot(vec3(COLOR.r, COLOR.g, COLOR.b), vec3(0.299000, 0.587000, 0.114000)), dot(vec3(COLOR.r, COLOR.g, COLOR.b), vec3(0.299000, 0.587000, 0.114000)), dot(vec3(COLOR.r, COLOR.g, COLOR.b), vec3(0.299000, 0.587000, 0.114000)), COLOR.a));
+	}
+}
@@ -209,3 +209,41 @@ func (s CubeSampler[T]) SampleGrad(uv, dPdx, dPdy gpu.Vec3) T { //glsl:textureGr
 func (s CubeSampler[T]) SampleLOD(uv gpu.Vec3, lod gpu.Float) T { //glsl:textureLod(samplerCube,vec3,float)rgba
 	return gpu.NewQuadExpression[T](gpu.Fn("textureLod", s, uv, lod))
 }
+
+// Sampler2DArray is the GLSL name for an [ArraySampler2D].
+type Sampler2DArray[T AnyData] = ArraySampler2D[T]
+
+// SamplerCube is the GLSL name for a [CubeSampler].
+type SamplerCube[T AnyData] = CubeSampler[T]
+
+// SamplerCubeArray samples an array of cubemaps, the w component of the uv selects the layer.
+type SamplerCubeArray[T AnyData] struct{ gpu.CubeArraySampler[T] }
+
+func (s SamplerCubeArray[T]) Sample(uv gpu.Vec4) T { //glsl:texture(samplerCubeArray,vec4)rgba
+	return gpu.NewQuadExpression[T](gpu.Fn("texture", s, uv))
+}
+func (s SamplerCubeArray[T]) SampleWithBias(uv gpu.Vec4, bias gpu.Float) T { //glsl:texture(samplerCubeArray,vec4,float)rgba
+	return gpu.NewQuadExpression[T](gpu.Fn("texture", s, uv, bias))
+}
+func (s SamplerCubeArray[T]) SampleSize(lod gpu.Int) gpu.Vec3i { //glsl:textureSize(samplerCubeArray,int)ivec3
+	return gpu.NewVec3iExpression(gpu.Fn("textureSize", s, lod))
+}
+func (s SamplerCubeArray[T]) SampleGrad(uv gpu.Vec4, dPdx, dPdy gpu.Vec3) T { //glsl:textureGrad(samplerCubeArray,vec4,vec3,vec3)rgba
+	return gpu.NewQuadExpression[T](gpu.Fn("textureGrad", s, uv, dPdx, dPdy))
+}
+func (s SamplerCubeArray[T]) SampleLOD(uv gpu.Vec4, lod gpu.Float) T { //glsl:textureLod(samplerCubeArray,vec4,float)rgba
+	return gpu.NewQuadExpression[T](gpu.Fn("textureLod", s, uv, lod))
+}
+
+// SamplerExternalOES samples an external texture, such as a camera feed on Android.
+type SamplerExternalOES[T AnyData] struct{ gpu.ExternalSampler[T] }
+
+func (s SamplerExternalOES[T]) Sample(uv gpu.Vec2) T { //glsl:texture(samplerExternalOES,vec2)rgba
+	return gpu.NewQuadExpression[T](gpu.Fn("texture", s, uv))
+}
+func (s SamplerExternalOES[T]) SampleWithBias(uv gpu.Vec2, bias gpu.Float) T { //glsl:texture(samplerExternalOES,vec2,float)rgba
+	return gpu.NewQuadExpression[T](gpu.Fn("texture", s, uv, bias))
+}
+func (s SamplerExternalOES[T]) SampleSize(lod gpu.Int) gpu.Vec2i { //glsl:textureSize(samplerExternalOES,int)ivec2
+	return gpu.NewVec2iExpression(gpu.Fn("textureSize", s, lod))
+}
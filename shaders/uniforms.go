@@ -0,0 +1,286 @@
+package shaders
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"graphics.gd/shaders/internal/gpu"
+	"graphics.gd/variant/String"
+)
+
+// Global uniforms can be added inside a shader struct, their values are shared by all shaders
+// and are configured in the project settings (under shader_globals) or with
+// RenderingServer.GlobalShaderParameterAdd.
+type Global[T gpu.Evaluator] struct {
+	value T
+}
+
+// Value returns the value of the global uniform.
+func (g Global[T]) Value() T { return g.value }
+
+// qualified uniforms are declared with a qualifier before the uniform keyword.
+type qualified interface {
+	qualifier() string
+	element() reflect.Type
+	identify(gpu.Identifier)
+}
+
+func (*PerInstance[T]) qualifier() string            { return "instance" }
+func (*PerInstance[T]) element() reflect.Type        { return reflect.TypeFor[T]() }
+func (p *PerInstance[T]) identify(id gpu.Identifier) { identify(reflect.ValueOf(&p.value).Elem(), id) }
+
+func (*Global[T]) qualifier() string            { return "global" }
+func (*Global[T]) element() reflect.Type        { return reflect.TypeFor[T]() }
+func (g *Global[T]) identify(id gpu.Identifier) { identify(reflect.ValueOf(&g.value).Elem(), id) }
+
+// Option configures the declaration of a uniform, see [Hint].
+type Option interface {
+	declare(*declaration)
+}
+
+// Uniform configures the declaration of a uniform field, shaders can provide these by implementing
+// a Uniforms method:
+//
+//	func (s *MyShader) Uniforms() []shaders.Uniform {
+//		return []shaders.Uniform{
+//			shaders.Hint(&s.Tint, shaders.SourceColor, shaders.Default(rgba.New(1.0, 1.0, 1.0, 1.0))),
+//			shaders.Hint(&s.Strength, shaders.HintRange(0, 1, 0.01)),
+//			shaders.Hint(&s.Screen, shaders.ScreenTexture, shaders.FilterLinearMipmap),
+//		}
+//	}
+type Uniform struct {
+	field   any
+	options []Option
+}
+
+// Hint returns the given options for the uniform field.
+func Hint[T any](uniform *T, options ...Option) Uniform {
+	return Uniform{field: uniform, options: options}
+}
+
+// hint is a GLSL uniform hint, some can only be applied to samplers.
+type hint struct {
+	glsl    string
+	sampler bool
+}
+
+func (h hint) declare(d *declaration) {
+	if h.sampler && !d.sampler {
+		panic(fmt.Sprintf("shaders.Hint: %s can only be applied to a sampler, not uniform %s", h.glsl, d.name))
+	}
+	d.hints = append(d.hints, h.glsl)
+}
+
+var (
+	SourceColor Option = hint{"source_color", false} // Value is an sRGB color (or texture), converted to linear when read.
+	HintNormal  Option = hint{"hint_normal", true}   // Texture is a normal map.
+
+	HintDefaultWhite       Option = hint{"hint_default_white", true}       // Texture defaults to opaque white.
+	HintDefaultBlack       Option = hint{"hint_default_black", true}       // Texture defaults to opaque black.
+	HintDefaultTransparent Option = hint{"hint_default_transparent", true} // Texture defaults to transparent black.
+	HintAnisotropy         Option = hint{"hint_anisotropy", true}          // Texture is a flowmap, defaulting to right.
+
+	HintRoughnessR      Option = hint{"hint_roughness_r", true}      // Texture is a roughness limiter, read from the red channel.
+	HintRoughnessG      Option = hint{"hint_roughness_g", true}      // Texture is a roughness limiter, read from the green channel.
+	HintRoughnessB      Option = hint{"hint_roughness_b", true}      // Texture is a roughness limiter, read from the blue channel.
+	HintRoughnessA      Option = hint{"hint_roughness_a", true}      // Texture is a roughness limiter, read from the alpha channel.
+	HintRoughnessNormal Option = hint{"hint_roughness_normal", true} // Texture is a roughness limiter, based on a normal map.
+	HintRoughnessGray   Option = hint{"hint_roughness_gray", true}   // Texture is a roughness limiter, read as grayscale.
+
+	FilterNearest                  Option = hint{"filter_nearest", true}
+	FilterLinear                   Option = hint{"filter_linear", true}
+	FilterNearestMipmap            Option = hint{"filter_nearest_mipmap", true}
+	FilterLinearMipmap             Option = hint{"filter_linear_mipmap", true}
+	FilterNearestMipmapAnisotropic Option = hint{"filter_nearest_mipmap_anisotropic", true}
+	FilterLinearMipmapAnisotropic  Option = hint{"filter_linear_mipmap_anisotropic", true}
+	RepeatEnable                   Option = hint{"repeat_enable", true}
+	RepeatDisable                  Option = hint{"repeat_disable", true}
+	ScreenTexture                  Option = hint{"hint_screen_texture", true}           // Sampler reads the screen behind the object.
+	DepthTexture                   Option = hint{"hint_depth_texture", true}            // Sampler reads the depth buffer.
+	NormalRoughnessTexture         Option = hint{"hint_normal_roughness_texture", true} // Sampler reads the normal/roughness buffer (Forward+ only).
+)
+
+// hints that can be written in the options of a gd struct tag, ie. `gd:"name,source_color"`.
+var hints = map[string]Option{}
+
+// splitHints splits the options of a gd struct tag on the commas that are not inside of parenthesis.
+func splitHints(options string) []string {
+	var list []string
+	var depth, start int
+	for i, r := range options {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				list = append(list, options[start:i])
+				start = i + 1
+			}
+		}
+	}
+	list = append(list, options[start:])
+	return list
+}
+
+// parseHint parses a hint from the options of a gd struct tag, for the given uniform. The GLSL
+// form of hint_range, ie. `gd:"name,hint_range(0,1,0.1)"` and the names without the hint_ prefix,
+// ie. `gd:"name,screen_texture"` are deprecated, they are only parsed into their typed [Option]
+// for shaders written before typed options existed, use [HintRange] in a Uniforms method instead.
+func parseHint(name, uniform string) Option {
+	if option, ok := hints[name]; ok {
+		return option
+	}
+	if option, ok := hints["hint_"+name]; ok {
+		return option
+	}
+	if args, ok := strings.CutPrefix(name, "hint_range("); ok && strings.HasSuffix(args, ")") {
+		var values []float64
+		for arg := range strings.SplitSeq(strings.TrimSuffix(args, ")"), ",") {
+			value, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+			if err != nil {
+				panic(fmt.Sprintf("shaders: invalid hint %q on uniform %s: %v", name, uniform, err))
+			}
+			values = append(values, value)
+		}
+		if len(values) < 2 || len(values) > 3 {
+			panic(fmt.Sprintf("shaders: invalid hint %q on uniform %s (want hint_range(min, max[, step]))", name, uniform))
+		}
+		return HintRange(values[0], values[1], values[2:]...)
+	}
+	panic(fmt.Sprintf("shaders: unknown hint %q on uniform %s", name, uniform))
+}
+
+func init() {
+	for _, option := range []Option{SourceColor, HintNormal, HintDefaultWhite, HintDefaultBlack, HintDefaultTransparent,
+		HintAnisotropy, HintRoughnessR, HintRoughnessG, HintRoughnessB, HintRoughnessA, HintRoughnessNormal,
+		HintRoughnessGray, FilterNearest, FilterLinear, FilterNearestMipmap, FilterLinearMipmap,
+		FilterNearestMipmapAnisotropic, FilterLinearMipmapAnisotropic, RepeatEnable, RepeatDisable,
+		ScreenTexture, DepthTexture, NormalRoughnessTexture} {
+		hints[option.(hint).glsl] = option
+	}
+}
+
+type hintRange struct {
+	min, max, step float64
+}
+
+// HintRange limits the uniform to the given range, for display in the inspector. The step is
+// optional.
+func HintRange(min, max float64, step ...float64) Option {
+	r := hintRange{min: min, max: max}
+	if len(step) > 0 {
+		r.step = step[0]
+	}
+	return r
+}
+
+func (r hintRange) declare(d *declaration) {
+	if d.glsl != "float" && d.glsl != "int" {
+		panic(fmt.Sprintf("shaders.HintRange: can only be applied to a float or int, not uniform %s", d.name))
+	}
+	if r.step != 0 {
+		d.hints = append(d.hints, fmt.Sprintf("hint_range(%v, %v, %v)", r.min, r.max, r.step))
+	} else {
+		d.hints = append(d.hints, fmt.Sprintf("hint_range(%v, %v)", r.min, r.max))
+	}
+}
+
+type defaultValue struct {
+	rtype reflect.Type
+	value gpu.Evaluator
+}
+
+// Default value of the uniform, must be the same type as the uniform.
+func Default[T gpu.Evaluator](value T) Option {
+	return defaultValue{rtype: reflect.TypeFor[T](), value: value}
+}
+
+func (v defaultValue) declare(d *declaration) {
+	if v.rtype != d.rtype {
+		panic(fmt.Sprintf("shaders.Default: %v does not match the %v type of uniform %s", v.rtype, d.rtype, d.name))
+	}
+	d.value = v.value
+}
+
+// declaration of a uniform.
+type declaration struct {
+	name      string
+	rtype     reflect.Type
+	glsl      string
+	qualifier string
+	sampler   bool
+	hints     []string
+	value     gpu.Evaluator
+}
+
+// compileUniforms links the uniform fields of the program and declares them, returning their
+// declarations.
+func compileUniforms(w io.Writer, prog Any) []declaration {
+	var declarations []declaration
+	var configured []Uniform
+	if uniforms, ok := prog.(interface{ Uniforms() []Uniform }); ok {
+		configured = uniforms.Uniforms()
+	}
+	value := reflect.ValueOf(prog).Elem()
+	rtype := value.Type()
+	for i := range rtype.NumField() {
+		field := rtype.Field(i)
+		if field.Name == "Shader" || !field.IsExported() {
+			continue
+		}
+		decl := declaration{name: String.ToSnakeCase(field.Name), rtype: field.Type}
+		var options []Option
+		if tag := field.Tag.Get("gd"); tag != "" {
+			tag, rest, _ := strings.Cut(tag, ",")
+			if tag != "" {
+				decl.name = tag
+			}
+			for _, name := range splitHints(rest) {
+				if name = strings.TrimSpace(name); name != "" {
+					options = append(options, parseHint(name, decl.name))
+				}
+			}
+		}
+		ptr := value.Field(i).Addr().Interface()
+		for _, uniform := range configured {
+			if uniform.field == ptr {
+				options = append(options, uniform.options...)
+			}
+		}
+		if q, ok := ptr.(qualified); ok {
+			decl.qualifier = q.qualifier()
+			decl.rtype = q.element()
+			q.identify(gpu.Identifier(decl.name))
+		} else {
+			gpu.Set(ptr.(gpu.Pointer), gpu.Uniform(decl.name, prog))
+		}
+		decl.glsl = gpu.TypeName(decl.rtype)
+		decl.sampler = strings.Contains(decl.glsl, "sampler")
+		for _, option := range options {
+			option.declare(&decl)
+		}
+		if decl.qualifier == "global" && (len(decl.hints) > 0 || decl.value != nil) {
+			panic(fmt.Sprintf("shaders: global uniform %s cannot have hints or a default value", decl.name))
+		}
+		if decl.qualifier != "" {
+			fmt.Fprintf(w, "%s ", decl.qualifier)
+		}
+		fmt.Fprintf(w, "uniform %s %s", decl.glsl, decl.name)
+		if len(decl.hints) > 0 {
+			fmt.Fprintf(w, " : %s", strings.Join(decl.hints, ", "))
+		}
+		if decl.value != nil {
+			fmt.Fprintf(w, " = ")
+			compileExpression(w, decl.value)
+		}
+		fmt.Fprintf(w, ";\n")
+		declarations = append(declarations, decl)
+	}
+	fmt.Fprintln(w)
+	return declarations
+}
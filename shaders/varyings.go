@@ -0,0 +1,143 @@
+package shaders
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"graphics.gd/shaders/internal/gpu"
+	"graphics.gd/variant/String"
+)
+
+// varying is a custom field added to a stage struct by embedding the struct of a pipeline, it is
+// declared as a GLSL varying, so that it can be written by one stage and read by the next.
+//
+//	type Fragment struct {
+//		Spatial.Fragment
+//
+//		Sway  float.X               // varying float sway;
+//		Layer int.X  `gd:"layer"`   // varying flat int layer;
+//		Tint  vec3.RGB `gd:",flat"` // varying flat vec3 tint;
+//	}
+type varying struct {
+	name          string
+	glsl          string
+	interpolation string // flat, smooth or empty for the default.
+}
+
+// custom returns the varying for the field of the stage struct, built-ins are declared by the
+// pipeline packages, or tagged with their (upper case) GLSL name.
+func custom(owner reflect.Type, field reflect.StructField) (varying, bool) {
+	if strings.HasPrefix(owner.PkgPath(), "graphics.gd/shaders/") || !field.IsExported() || field.Anonymous ||
+		!field.Type.Implements(reflect.TypeFor[gpu.Evaluator]()) {
+		return varying{}, false
+	}
+	tag := field.Tag.Get("gd")
+	if tag == "-" {
+		return varying{}, false
+	}
+	name, rest, _ := strings.Cut(tag, ",")
+	if name != "" && name == strings.ToUpper(name) {
+		return varying{}, false
+	}
+	if name == "" {
+		name = String.ToSnakeCase(field.Name)
+	}
+	v := varying{name: name, glsl: gpu.TypeName(field.Type)}
+	for option := range strings.SplitSeq(rest, ",") {
+		switch option = strings.TrimSpace(option); option {
+		case "":
+		case "flat", "smooth":
+			v.interpolation = option
+		default:
+			panic(fmt.Sprintf("shaders: unknown option %q on varying %s (expected flat or smooth)", option, name))
+		}
+	}
+	switch {
+	case strings.Contains(v.glsl, "sampler"), strings.HasPrefix(v.glsl, "b"):
+		panic(fmt.Sprintf("shaders: varying %s cannot be a %s", name, v.glsl))
+	case strings.HasPrefix(v.glsl, "i"), strings.HasPrefix(v.glsl, "u"):
+		if v.interpolation == "smooth" {
+			panic(fmt.Sprintf("shaders: varying %s is a %s, so it cannot be smooth", name, v.glsl))
+		}
+		v.interpolation = "flat" // integers are never interpolated.
+	}
+	return v, true
+}
+
+// stageFields calls fn with the GLSL name and index of each GPU field of the stage struct, which
+// are either built-ins or varyings, embedded and nested structs are walked recursively.
+func stageFields(rtype reflect.Type, fn func(name string, index []int)) {
+	evaluator := reflect.TypeFor[gpu.Evaluator]()
+	for i := range rtype.NumField() {
+		field := rtype.Field(i)
+		if v, ok := custom(rtype, field); ok {
+			fn(v.name, field.Index)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if field.Type.Implements(evaluator) {
+			if name, _, _ := strings.Cut(field.Tag.Get("gd"), ","); name != "" && name != "-" {
+				fn(name, field.Index)
+			}
+		} else if field.Type.Kind() == reflect.Struct {
+			stageFields(field.Type, func(name string, index []int) {
+				fn(name, append([]int{i}, index...))
+			})
+		}
+	}
+}
+
+// varyings returns the varyings added to each of the stage structs, in order.
+func varyings(stages ...reflect.Type) []varying {
+	var list []varying
+	var walk func(rtype reflect.Type)
+	walk = func(rtype reflect.Type) {
+		for i := range rtype.NumField() {
+			field := rtype.Field(i)
+			if v, ok := custom(rtype, field); ok {
+				for _, existing := range list {
+					if existing.name == v.name && existing != v {
+						panic(fmt.Sprintf("shaders: varying %s is declared as both a %s %s and a %s %s", v.name,
+							existing.interpolation, existing.glsl, v.interpolation, v.glsl))
+					}
+				}
+				if !slices.Contains(list, v) {
+					list = append(list, v)
+				}
+			} else if field.IsExported() && field.Type.Kind() == reflect.Struct &&
+				!field.Type.Implements(reflect.TypeFor[gpu.Evaluator]()) {
+				walk(field.Type)
+			}
+		}
+	}
+	for _, rtype := range stages {
+		if rtype.Kind() == reflect.Struct {
+			walk(rtype)
+		}
+	}
+	return list
+}
+
+// compileVaryings declares the varyings of the program, which are only supported by the
+// spatial and canvas_item shader types.
+func compileVaryings(w io.Writer, prog Any, list []varying) {
+	if len(list) == 0 {
+		return
+	}
+	if shaderType := prog.ShaderType(); shaderType != "spatial" && shaderType != "canvas_item" {
+		panic(fmt.Sprintf("shaders: %s shaders do not support varyings (such as %s)", shaderType, list[0].name))
+	}
+	for _, v := range list {
+		fmt.Fprintf(w, "varying ")
+		if v.interpolation != "" {
+			fmt.Fprintf(w, "%s ", v.interpolation)
+		}
+		fmt.Fprintf(w, "%s %s;\n", v.glsl, v.name)
+	}
+	fmt.Fprintln(w)
+}
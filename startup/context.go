@@ -0,0 +1,10 @@
+package startup
+
+import "context"
+
+var engine, shutdownBegan = context.WithCancel(context.Background())
+
+// Context returns a context that is cancelled when the engine begins to shut down. Goroutines
+// that make use of the engine should stop when it is done, so that they do not outlive the
+// engine.
+func Context() context.Context { return engine }
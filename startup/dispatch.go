@@ -0,0 +1,120 @@
+package startup
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	gd "graphics.gd/internal"
+	"graphics.gd/internal/gdextension"
+)
+
+// dispatch is the queue of functions waiting to be run on the main thread, it is
+// drained each frame, alongside [Callable.Cycle] and [pointers.Cycle].
+var dispatch struct {
+	sync.Mutex
+	queue []func()
+}
+
+// mainThreadID is the engine's identifier for the main thread, zero until the engine
+// has started up.
+var mainThreadID atomic.Int64
+
+// Go schedules fn to run on the main thread during the next frame, it returns immediately.
+// Use this to make engine calls from goroutines, which must not call into the engine
+// directly.
+func Go(fn func()) {
+	dispatch.Lock()
+	dispatch.queue = append(dispatch.queue, fn)
+	dispatch.Unlock()
+}
+
+// Do runs fn on the main thread and blocks until it has returned. If called from the
+// main thread, fn is run immediately. If fn panics, the panic is propagated to the
+// caller of Do. If the engine shuts down before fn gets to run, Do returns without
+// waiting for it.
+func Do(fn func()) {
+	if onMainThread() {
+		fn()
+		return
+	}
+	var done = make(chan any, 1)
+	Go(func() {
+		defer func() { done <- recover() }()
+		fn()
+	})
+	select {
+	case r := <-done:
+		if r != nil {
+			panic(r)
+		}
+	case <-engine.Done():
+	}
+}
+
+// threads are the OS methods that identify the calling thread. They are called directly
+// instead of through the OS class, as asking which thread the caller is on must not itself be
+// subject to [checkThread].
+var threads struct {
+	get_thread_caller_id gdextension.MethodForClass `hash:"3905245786"`
+	get_main_thread_id   gdextension.MethodForClass `hash:"3905245786"`
+}
+
+// threadsOS is the OS singleton that [threads] are called on, along with its class name.
+var (
+	threadsOS   gdextension.Object
+	threadsName gdextension.StringName
+)
+
+// callerThread returns the engine's identifier for the calling thread.
+var callerThread = func() int64 {
+	return gdextension.Call[int64](threadsOS, threads.get_thread_caller_id, gdextension.SizeInt, &struct{}{})
+}
+
+// onMainThread reports whether the caller is running on the engine's main thread.
+func onMainThread() bool {
+	id := mainThreadID.Load()
+	return id != 0 && callerThread() == id
+}
+
+// cycle runs all of the functions queued by [Go] and [Do], in the order they were queued.
+// Panics are handled according to the panic policy, so that one function cannot prevent
+// the rest of the queue from running.
+func cycle() {
+	dispatch.Lock()
+	queue := dispatch.queue
+	dispatch.queue = nil
+	dispatch.Unlock()
+	for _, fn := range queue {
+		func() {
+			defer gd.Recover()
+			fn()
+		}()
+	}
+}
+
+// checkThread panics if the engine is called from any thread other than the main thread.
+// It is enabled by setting the GDTHREADCHECK environment variable to 1.
+func checkThread() {
+	if id := callerThread(); id != mainThreadID.Load() {
+		panic(fmt.Sprintf("graphics.gd: engine called from thread %d, instead of the main thread (use startup.Do or startup.Go to call the engine from a goroutine)", id))
+	}
+}
+
+func init() {
+	gd.Links = append(gd.Links, func() {
+		threadsName = gdextension.Host.Strings.Intern.UTF8("OS")
+		gd.LinkMethods(threadsName, &threads, false)
+	})
+	gd.RegisterCleanup(func() {
+		gdextension.Free(gdextension.TypeStringName, &threadsName)
+	})
+	gd.PostStartupFunctions = append(gd.PostStartupFunctions, func() {
+		threadsOS = gdextension.Host.Objects.Global(threadsName)
+		mainThreadID.Store(gdextension.Call[int64](threadsOS, threads.get_main_thread_id, gdextension.SizeInt, &struct{}{}))
+		if os.Getenv("GDTHREADCHECK") == "1" {
+			gd.ThreadCheck = checkThread
+		}
+	})
+}
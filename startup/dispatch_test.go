@@ -0,0 +1,130 @@
+package startup
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	gd "graphics.gd/internal"
+	"graphics.gd/internal/gdextension"
+)
+
+// goroutineID identifies the calling goroutine, it stands in for the engine's thread identifiers.
+func goroutineID() int64 {
+	var buf [64]byte
+	fields := strings.Fields(string(buf[:runtime.Stack(buf[:], false)]))
+	id, _ := strconv.ParseInt(fields[1], 10, 64)
+	return id
+}
+
+// mainLoop runs [cycle] on a goroutine that stands in for the main thread, with the thread check
+// enabled, as it would be with GDTHREADCHECK=1, until the test ends.
+func mainLoop(t *testing.T) {
+	t.Helper()
+	caller, check := callerThread, gd.ThreadCheck
+	callerThread, gd.ThreadCheck = goroutineID, checkThread
+	started, stop, stopped := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		mainThreadID.Store(goroutineID())
+		close(started)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Millisecond):
+				cycle()
+			}
+		}
+	}()
+	<-started
+	t.Cleanup(func() {
+		close(stop)
+		<-stopped
+		mainThreadID.Store(0)
+		callerThread, gd.ThreadCheck = caller, check
+	})
+}
+
+// recoverPanics handles panics with [gd.PanicContinue] until the test ends, logging them to the
+// returned slice instead of the engine.
+func recoverPanics(t *testing.T) *[]string {
+	t.Helper()
+	var logged []string
+	policy, logError := gd.OnPanic, gdextension.Host.Log.Error
+	gd.OnPanic = gd.PanicContinue
+	gdextension.Host.Log.Error = func(text, code, fn, file string, line int32, notify_editor bool) {
+		logged = append(logged, text)
+	}
+	t.Cleanup(func() {
+		gd.OnPanic, gdextension.Host.Log.Error = policy, logError
+	})
+	return &logged
+}
+
+func TestDoFromGoroutine(t *testing.T) {
+	mainLoop(t)
+	var ranOn int64
+	Do(func() {
+		gd.ThreadCheck()
+		ranOn = goroutineID()
+	})
+	if ranOn != mainThreadID.Load() {
+		t.Fatalf("Do ran on %d, instead of the main thread %d", ranOn, mainThreadID.Load())
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("the thread check did not panic for an engine call made from a goroutine")
+		}
+	}()
+	gd.ThreadCheck()
+}
+
+func TestDoPanics(t *testing.T) {
+	mainLoop(t)
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatalf("Do panicked with %v, want boom", r)
+		}
+	}()
+	Do(func() { panic("boom") })
+	t.Fatal("Do did not propagate the panic")
+}
+
+func TestDoOnMainThread(t *testing.T) {
+	caller := callerThread
+	callerThread = goroutineID
+	mainThreadID.Store(goroutineID())
+	defer func() {
+		mainThreadID.Store(0)
+		callerThread = caller
+	}()
+	var ran bool
+	Do(func() { ran = true })
+	if !ran {
+		t.Fatal("Do did not run immediately on the main thread")
+	}
+}
+
+func TestCycleOrder(t *testing.T) {
+	logged := recoverPanics(t)
+	var order []int
+	Go(func() { order = append(order, 1) })
+	Go(func() { panic("boom") })
+	Go(func() { order = append(order, 2) })
+	Go(func() { Go(func() { order = append(order, 4) }) })
+	Go(func() { order = append(order, 3) })
+	cycle()
+	if len(order) != 3 || order[0] != 1 || order[1] != 2 || order[2] != 3 {
+		t.Fatalf("first cycle ran %v, want [1 2 3]", order)
+	}
+	if len(*logged) != 1 || !strings.HasPrefix((*logged)[0], "boom") {
+		t.Fatalf("expected the panic to be logged once, got %q", *logged)
+	}
+	cycle()
+	if len(order) != 4 || order[3] != 4 {
+		t.Fatalf("second cycle ran %v, want [1 2 3 4]", order)
+	}
+}
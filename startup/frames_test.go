@@ -0,0 +1,46 @@
+package startup
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFramesBreakOnPhysicsFrame(t *testing.T) {
+	var loop goMainLoop
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for frame := range Frames() {
+			if frame == PhysicsFrame {
+				break
+			}
+		}
+	}()
+	step := func(name string, fn func() bool) bool {
+		t.Helper()
+		result := make(chan bool, 1)
+		go func() { result <- fn() }()
+		select {
+		case ended := <-result:
+			return ended
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s deadlocked", name)
+			return false
+		}
+	}
+	if step("Process", func() bool { return loop.Process(1.0 / 60) }) {
+		t.Fatal("main loop ended before main received a frame")
+	}
+	if !step("PhysicsProcess", func() bool { return loop.PhysicsProcess(1.0 / 60) }) {
+		t.Fatal("PhysicsProcess did not end the main loop after main returned")
+	}
+	if !step("Process", func() bool { return loop.Process(1.0 / 60) }) {
+		t.Fatal("Process did not end the main loop after main returned")
+	}
+	loop.Finalize()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("main did not return after Finalize")
+	}
+}
@@ -29,8 +29,14 @@ func (gr goRuntime) ExitTree() {
 	}).CallDeferred()
 }
 
+func (goRuntime) PhysicsProcess(delta Float.X) {
+	Callable.PhysicsCycle()
+}
+
 func (goRuntime) Process(delta Float.X) {
+	Callable.Advance(float64(delta))
 	gd.NewCallable(func() {
+		cycle()
 		Callable.Cycle()
 		pointers.Cycle()
 	}).CallDeferred()
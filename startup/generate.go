@@ -0,0 +1,29 @@
+package startup
+
+import (
+	"fmt"
+	"os"
+
+	gd "graphics.gd/internal"
+)
+
+// generate runs the generator requested by 'gd generate' through the GDGENERATE environment
+// variable and then exits, as the program is being run without the engine. Every entry point
+// ([MainLoop], [LoadingScene] and thereby [Scene] and [Server], along with [Rendering] and [Frames])
+// must call it before waiting for the engine, which never starts up under 'gd generate'.
+func generate() {
+	kind := os.Getenv("GDGENERATE")
+	if kind == "" || gd.Linked {
+		return
+	}
+	generator, ok := gd.Generators[kind]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "gd generate %s: nothing to generate, is the package imported?\n", kind)
+		os.Exit(1)
+	}
+	if err := generator(os.Getenv("GDGENERATE_DIR")); err != nil {
+		fmt.Fprintf(os.Stderr, "gd generate %s: %v\n", kind, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
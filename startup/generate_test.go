@@ -0,0 +1,36 @@
+package startup
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gd "graphics.gd/internal"
+)
+
+// TestFramesGenerate checks that 'gd generate' runs its generator from a program that only calls
+// Frames, rather than waiting for an engine that never starts.
+func TestFramesGenerate(t *testing.T) {
+	if dir := os.Getenv("GDGENERATE_DIR"); dir != "" {
+		gd.Generators["test"] = func(dir string) error {
+			return os.WriteFile(filepath.Join(dir, "generated"), nil, 0644)
+		}
+		for range Frames() {
+		}
+		return
+	}
+	dir := t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, os.Args[0], "-test.run=^TestFramesGenerate$")
+	cmd.Env = append(os.Environ(), "GDGENERATE=test", "GDGENERATE_DIR="+dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "generated")); err != nil {
+		t.Fatal("generator was not run:", err)
+	}
+}
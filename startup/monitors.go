@@ -0,0 +1,94 @@
+package startup
+
+import (
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+
+	EngineClass "graphics.gd/classdb/Engine"
+	"graphics.gd/classdb/Performance"
+	gd "graphics.gd/internal"
+	"graphics.gd/internal/pointers"
+	"graphics.gd/variant/Callable"
+)
+
+// monitors of the Go runtime, shown in the editor's debugger under the Monitors tab.
+var monitors = map[string]func() float64{
+	"Go/Goroutines": func() float64 {
+		return float64(runtime.NumGoroutine())
+	},
+	"Go/Heap In Use (MiB)": func() float64 {
+		var sample = []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+		metrics.Read(sample)
+		if sample[0].Value.Kind() != metrics.KindUint64 {
+			return 0
+		}
+		return float64(sample[0].Value.Uint64()) / (1 << 20)
+	},
+	"Go/GC Pause (ms)": func() float64 {
+		var stats debug.GCStats
+		debug.ReadGCStats(&stats)
+		if len(stats.Pause) == 0 {
+			return 0
+		}
+		return float64(stats.Pause[0].Microseconds()) / 1000
+	},
+	"Go/GC Cycles": func() float64 {
+		var stats debug.GCStats
+		debug.ReadGCStats(&stats)
+		return float64(stats.NumGC)
+	},
+	"Go/Cgo Calls Per Frame": cgoCallsPerFrame(),
+	"Go/Live Pointers":       livePointers,
+	"Go/Pinned Pointers":     pinnedPointers,
+}
+
+var livePointers, pinnedPointers = pointerCounts()
+
+// pointerCounts returns monitors of the number of live and pinned pointers, which share a single
+// pass over the pointer tables per frame.
+func pointerCounts() (live, pinned func() float64) {
+	var (
+		lastFrame    int64 = -1
+		nLive, nPins int
+	)
+	count := func() {
+		if frame := int64(EngineClass.GetProcessFrames()); frame != lastFrame {
+			lastFrame = frame
+			nLive, nPins = pointers.Count()
+		}
+	}
+	live = func() float64 {
+		count()
+		return float64(nLive)
+	}
+	pinned = func() float64 {
+		count()
+		return float64(nPins)
+	}
+	return live, pinned
+}
+
+// cgoCallsPerFrame returns a monitor of the average number of cgo calls per frame, since the
+// monitor was last sampled.
+func cgoCallsPerFrame() func() float64 {
+	var lastCalls, lastFrame int64
+	return func() float64 {
+		calls, frame := runtime.NumCgoCall(), int64(EngineClass.GetProcessFrames())
+		defer func() { lastCalls, lastFrame = calls, frame }()
+		if frame <= lastFrame {
+			return 0
+		}
+		return float64(calls-lastCalls) / float64(frame-lastFrame)
+	}
+}
+
+func init() {
+	gd.PostStartupFunctions = append(gd.PostStartupFunctions, func() {
+		for id, monitor := range monitors {
+			if !Performance.HasCustomMonitor(id) {
+				Performance.AddCustomMonitor(id, Callable.New(monitor), nil)
+			}
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package startup
+
+import (
+	EngineClass "graphics.gd/classdb/Engine"
+	SceneTreeClass "graphics.gd/classdb/SceneTree"
+	gd "graphics.gd/internal"
+	"graphics.gd/variant/Object"
+)
+
+// PanicPolicy determines what happens when a Go virtual method, signal handler or [Callable.Function]
+// called by the engine panics. The panic is always reported as an engine error, along with its Go
+// stack trace.
+type PanicPolicy = gd.PanicPolicy
+
+const (
+	LogAndContinue = gd.PanicContinue // recover and continue running (default).
+	LogAndQuit     = gd.PanicQuit     // recover and quit gracefully.
+	Crash          = gd.PanicCrash    // crash the process (default when GOTRACEBACK=crash).
+)
+
+// OnPanic sets the policy for panics in Go code called by the engine.
+func OnPanic(policy PanicPolicy) { gd.OnPanic = policy }
+
+// CrashReports writes a crash report to the given file path whenever a panic occurs in Go code
+// called by the engine. The report includes the stack traces of all goroutines, along with the
+// engine version.
+func CrashReports(path string) { gd.CrashReport = path }
+
+// quitting is set when the main loop should end, for when there is no SceneTree to quit.
+var quitting bool
+
+// quit gracefully, via the main loop's Finalize path. Safe to call from any goroutine.
+func quit() {
+	Go(func() {
+		if tree, ok := Object.As[SceneTreeClass.Instance](EngineClass.GetMainLoop()); ok {
+			tree.Quit()
+			return
+		}
+		quitting = true
+	})
+}
+
+func init() {
+	gd.Quit = quit
+}
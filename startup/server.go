@@ -0,0 +1,41 @@
+package startup
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"graphics.gd/classdb/DisplayServer"
+	EngineClass "graphics.gd/classdb/Engine"
+)
+
+// Server starts up the SceneTree as a dedicated server, that ticks both physics and process frames
+// at the given rate (per second) with vsync disabled. Blocks until the engine shuts down. An interrupt
+// or SIGTERM will quit the SceneTree, such that the engine shuts down gracefully.
+//
+// A server should run without any display or audio, which the engine decides before it starts up, so
+// run it with 'gd run -server' (or pass --headless to the engine) and export it with 'gd build -server',
+// both of which select the headless display driver and the Dummy audio driver. Dedicated servers are
+// only supported on Linux. If the engine was started with a display, an error is raised and the server
+// runs in its window.
+func Server(tickRate int) {
+	LoadingScene()
+	if !EngineClass.IsEditorHint() && DisplayServer.GetName() != "headless" {
+		EngineClass.Raise(errors.New("startup.Server: the engine is not headless, run it with 'gd run -server' or --headless"))
+	}
+	EngineClass.SetPhysicsTicksPerSecond(tickRate)
+	EngineClass.SetMaxFps(tickRate)
+	DisplayServer.WindowSetVsyncMode(DisplayServer.VsyncDisabled, DisplayServer.MainWindowId)
+	var signals = make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			quit()
+		case <-engine.Done():
+		}
+		signal.Stop(signals)
+	}()
+	Scene()
+}
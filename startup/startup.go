@@ -5,6 +5,7 @@ import (
 	"iter"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 
 	"graphics.gd/classdb"
 	"graphics.gd/classdb/EditorInterface"
@@ -27,6 +28,7 @@ var shutdown = make(chan struct{})
 // MainLoop uses the given struct as the main loop implementation. This will take care of initialising
 // the Go runtime correctly, blocks until the main loop has shutdown.
 func MainLoop(loop MainLoopClass.Interface) {
+	generate()
 	if pause_main != nil {
 		if EngineClass.IsEditorHint() {
 			stop_main()
@@ -76,6 +78,7 @@ var loadingSceneWasCalled bool
 // editor-accessible classes before calling this function if you want them to be
 // available in the editor.
 func LoadingScene() {
+	generate()
 	loadingSceneWasCalled = true
 	classdb.Register[goSceneTree]()
 	if pause_main != nil {
@@ -126,13 +129,34 @@ func (loop goMainLoop) Initialize() {
 // Called each physics frame with the time since the last physics frame as argument ([param delta], in seconds). Equivalent to [method Node._physics_process].
 // If implemented, the method must return a boolean value. [code]true[/code] ends the main loop, while [code]false[/code] lets it proceed to the next frame.
 func (loop goMainLoop) PhysicsProcess(delta Float.X) bool {
+	defer Callable.PhysicsCycle()
 	if mainloop != nil {
 		return mainloop.PhysicsProcess(delta)
 	}
-	return false
+	if !physicsFrames {
+		return false
+	}
+	if mainReturned.Load() {
+		return true
+	}
+	dt, kind = delta, PhysicsFrame
+	if pause_main != nil {
+		close, _ := resume_main()
+		return close
+	}
+	frame_ready <- false
+	return <-frame_ready
 }
 
 var dt Float.X
+var kind Frame
+
+// physicsFrames is true when [Frames] is in use, such that physics frames are passed to main.
+var physicsFrames bool
+
+// mainReturned is set once main breaks out of [Rendering] or [Frames], such that the remaining
+// frame callbacks end the main loop, instead of waiting for main to take the next frame.
+var mainReturned atomic.Bool
 
 var frame_ready = make(chan bool)
 
@@ -141,10 +165,18 @@ var frame_ready = make(chan bool)
 func (loop goMainLoop) Process(delta Float.X) bool {
 	defer Callable.Cycle()
 	defer pointers.Cycle()
+	defer cycle()
+	if quitting {
+		return true
+	}
+	Callable.Advance(float64(delta))
 	if mainloop != nil {
 		return mainloop.Process(delta)
 	}
-	dt = delta
+	if mainReturned.Load() {
+		return true
+	}
+	dt, kind = delta, RenderFrame
 	if pause_main != nil {
 		close, _ := resume_main()
 		return close
@@ -157,6 +189,7 @@ var main_loop_shutdown = make(chan struct{})
 
 // Called before the program exits.
 func (loop goMainLoop) Finalize() {
+	shutdownBegan()
 	if mainloop != nil {
 		mainloop.Finalize()
 	} else if pause_main != nil {
@@ -178,17 +211,57 @@ func (loop goMainLoop) Finalize() {
 //			// finalize
 //		}
 func Rendering() iter.Seq[Float.X] {
+	frames := frames()
+	return func(yield func(Float.X) bool) {
+		for _, delta := range frames {
+			if !yield(delta) {
+				break
+			}
+		}
+	}
+}
+
+// Frame identifies the kind of frame yielded by [Frames].
+type Frame int
+
+const (
+	RenderFrame  Frame = iota // process (idle) frame, ready for rendering.
+	PhysicsFrame              // fixed-step physics frame.
+)
+
+// Frames is like [Rendering], except that it also yields each physics frame, such that a fixed-step
+// simulation can run from main alongside rendering, without a SceneTree. The delta of each
+// [PhysicsFrame] is the fixed physics step.
+//
+//	func main() {
+//		for frame, delta := range startup.Frames() {
+//			switch frame {
+//			case startup.PhysicsFrame:
+//				// step simulation
+//			case startup.RenderFrame:
+//				// render frame
+//			}
+//		}
+//	}
+func Frames() iter.Seq2[Frame, Float.X] {
+	physicsFrames = true
+	return frames()
+}
+
+func frames() iter.Seq2[Frame, Float.X] {
+	generate()
 	classdb.Register[goMainLoop]()
 	if pause_main != nil {
 		if EngineClass.IsEditorHint() {
 			stop_main()
 		}
 		pause_main(false) // We pause here until the engine has fully started up.
-		return func(yield func(Float.X) bool) {
+		return func(yield func(Frame, Float.X) bool) {
 			pause_main(false) // we pause here until the MainLoop initialize function is called.
 			for {
 				pause_main(false) // we pause here until the next frame is ready (next Process callback).
-				if !yield(dt) {
+				if !yield(kind, dt) {
+					mainReturned.Store(true)
 					break
 				}
 			}
@@ -196,11 +269,12 @@ func Rendering() iter.Seq[Float.X] {
 		}
 	} else {
 		<-frame_ready
-		return func(yield func(Float.X) bool) {
+		return func(yield func(Frame, Float.X) bool) {
 			frame_ready <- false
 			for {
 				<-frame_ready // we pause here until the next frame is ready (next Process callback).
-				if !yield(dt) {
+				if !yield(kind, dt) {
+					mainReturned.Store(true)
 					frame_ready <- true
 					break
 				}
@@ -47,11 +47,13 @@ func init() {
 		},
 		Exit: func(level gdextension.InitializationLevel) {
 			if level == 2 {
+				shutdownBegan()
 				for _, cleanup := range internal.Cleanups() {
 					cleanup()
 				}
 				pointers.Cycle()
 				pointers.Cycle()
+				pointers.ReportLeaks(os.Stderr)
 				if theMainFunctionIsWaitingForTheEngineToShutDown {
 					resume_main()
 				}
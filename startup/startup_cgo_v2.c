@@ -843,6 +843,31 @@ void gd_classdb_register_methods(uintptr_t class_name, uintptr_t methods) {
     }
 };
 
+void gd_classdb_register_virtual(uintptr_t class_name, uintptr_t name, uint32_t method_flags, uintptr_t return_value_info, uintptr_t arguments_info) {
+    static uintptr_t empty = 0;
+    property_list *return_value = (property_list *)return_value_info;
+    property_list *arguments = (property_list *)arguments_info;
+    GDExtensionClassVirtualMethodInfo info = {
+        .name = (GDExtensionStringNamePtr)&name,
+        .method_flags = method_flags,
+    };
+    if (return_value && return_value->push > 0) {
+        info.return_value = return_value->info[0];
+        info.return_value_metadata = return_value->meta[0];
+    } else {
+        info.return_value.type = GDEXTENSION_VARIANT_TYPE_NIL;
+        info.return_value.name = (GDExtensionStringNamePtr)&empty;
+        info.return_value.class_name = (GDExtensionStringNamePtr)&empty;
+        info.return_value.hint_string = (GDExtensionStringPtr)&empty;
+    }
+    if (arguments && arguments->push > 0) {
+        info.argument_count = arguments->push;
+        info.arguments = arguments->info;
+        info.arguments_metadata = arguments->meta;
+    }
+    gdextension_classdb_register_extension_class_virtual_method(cgo_library, (GDExtensionConstStringNamePtr)&class_name, &info);
+};
+
 void gd_classdb_register_constant(uintptr_t class_name, uintptr_t enum_name, uintptr_t name, INT64(value), bool bitfield) {
     gdextension_classdb_register_extension_class_integer_constant(cgo_library, (GDExtensionConstStringNamePtr)&class_name, (GDExtensionConstStringNamePtr)&enum_name, (GDExtensionConstStringNamePtr)&name, INT64_FROM(value), bitfield);
 };
@@ -1721,6 +1746,7 @@ EMSCRIPTEN_BINDINGS(my_module) {
 	function("gd_property_info_usage", &gd_property_info_usage, allow_raw_pointers());
 	function("gd_classdb_register", &gd_classdb_register, allow_raw_pointers());
 	function("gd_classdb_register_methods", &gd_classdb_register_methods, allow_raw_pointers());
+	function("gd_classdb_register_virtual", &gd_classdb_register_virtual, allow_raw_pointers());
 	function("gd_classdb_register_constant", &gd_classdb_register_constant, allow_raw_pointers());
 	function("gd_classdb_register_property", &gd_classdb_register_property, allow_raw_pointers());
 	function("gd_classdb_register_property_indexed", &gd_classdb_register_property_indexed, allow_raw_pointers());
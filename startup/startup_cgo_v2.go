@@ -398,6 +398,10 @@ func init() {
 		C.gd_classdb_register_methods(C.uintptr_t(p0[0]), C.uintptr_t(p1))
 		return
 	}
+	gdextension.Host.ClassDB.Register.Virtual = func(p0 gdextension.StringName, p1 gdextension.StringName, p2 gdextension.MethodFlags, p3 gdextension.PropertyList, p4 gdextension.PropertyList) {
+		C.gd_classdb_register_virtual(C.uintptr_t(p0[0]), C.uintptr_t(p1[0]), C.uint32_t(p2), C.uintptr_t(p3), C.uintptr_t(p4))
+		return
+	}
 	gdextension.Host.ClassDB.Register.Constant = func(p0 gdextension.StringName, p1 gdextension.StringName, p2 gdextension.StringName, p3 int64, p4 bool) {
 		C.gd_classdb_register_constant(C.uintptr_t(p0[0]), C.uintptr_t(p1[0]), C.uintptr_t(p2[0]), C.int64_t(p3), C.bool(p4))
 		return
@@ -42,6 +42,7 @@ uintptr_t gd_property_info_hint_string(uintptr_t);
 uint32_t gd_property_info_usage(uintptr_t);
 void gd_classdb_register(uintptr_t, uintptr_t, uintptr_t, bool, bool, bool, bool, uintptr_t);
 void gd_classdb_register_methods(uintptr_t, uintptr_t);
+void gd_classdb_register_virtual(uintptr_t, uintptr_t, uint32_t, uintptr_t, uintptr_t);
 void gd_classdb_register_constant(uintptr_t, uintptr_t, uintptr_t, int64_t, bool);
 void gd_classdb_register_property(uintptr_t, uintptr_t, uintptr_t, uintptr_t);
 void gd_classdb_register_property_indexed(uintptr_t, uintptr_t, uintptr_t, uintptr_t, int64_t);
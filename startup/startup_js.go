@@ -1,6 +1,8 @@
 package startup
 
 import (
+	"os"
+
 	gd "graphics.gd/internal"
 	internal "graphics.gd/internal"
 	"graphics.gd/internal/gdextension"
@@ -24,11 +26,13 @@ func init() {
 		},
 		Exit: func(level gdextension.InitializationLevel) {
 			if level == 2 {
+				shutdownBegan()
 				for _, cleanup := range gd.Cleanups() {
 					cleanup()
 				}
 				pointers.Cycle()
 				pointers.Cycle()
+				pointers.ReportLeaks(os.Stderr)
 				close(shutdown)
 				internal.Linked = false
 			}
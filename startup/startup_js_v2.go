@@ -55,6 +55,7 @@ func init() {
 		gd_property_info_usage                     js.Value
 		gd_classdb_register                        js.Value
 		gd_classdb_register_methods                js.Value
+		gd_classdb_register_virtual                js.Value
 		gd_classdb_register_constant               js.Value
 		gd_classdb_register_property               js.Value
 		gd_classdb_register_property_indexed       js.Value
@@ -235,6 +236,7 @@ func init() {
 		gd_property_info_usage = GD.Get("property_info_usage")
 		gd_classdb_register = GD.Get("classdb_register")
 		gd_classdb_register_methods = GD.Get("classdb_register_methods")
+		gd_classdb_register_virtual = GD.Get("classdb_register_virtual")
 		gd_classdb_register_constant = GD.Get("classdb_register_constant")
 		gd_classdb_register_property = GD.Get("classdb_register_property")
 		gd_classdb_register_property_indexed = GD.Get("classdb_register_property_indexed")
@@ -759,6 +761,11 @@ func init() {
 		gd_classdb_register_methods.Invoke(uint32(p0[0]), uint32(p1))
 		return
 	}
+	gdextension.Host.ClassDB.Register.Virtual = func(p0 gdextension.StringName, p1 gdextension.StringName, p2 gdextension.MethodFlags, p3 gdextension.PropertyList, p4 gdextension.PropertyList) {
+		setup()
+		gd_classdb_register_virtual.Invoke(uint32(p0[0]), uint32(p1[0]), uint32(p2), uint32(p3), uint32(p4))
+		return
+	}
 	gdextension.Host.ClassDB.Register.Constant = func(p0 gdextension.StringName, p1 gdextension.StringName, p2 gdextension.StringName, p3 int64, p4 bool) {
 		setup()
 		gd_classdb_register_constant.Invoke(uint32(p0[0]), uint32(p1[0]), uint32(p2[0]), uint32(*(*uint64)(unsafe.Pointer(&p3))>>32), uint32(*(*uint64)(unsafe.Pointer(&p3))&0xFFFFFFFF), p4)
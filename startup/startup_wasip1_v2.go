@@ -127,6 +127,9 @@ func gd_classdb_register(p0 uintptr, p1 uintptr, p2 uintptr, p3 bool, p4 bool, p
 //go:wasmimport gd classdb_register_methods
 func gd_classdb_register_methods(p0 uintptr, p1 uintptr)
 
+//go:wasmimport gd classdb_register_virtual
+func gd_classdb_register_virtual(p0 uintptr, p1 uintptr, p2 uint32, p3 uintptr, p4 uintptr)
+
 //go:wasmimport gd classdb_register_constant
 func gd_classdb_register_constant(p0 uintptr, p1 uintptr, p2 uintptr, p3 int64, p4 bool)
 
@@ -957,6 +960,10 @@ func init() {
 		gd_classdb_register_methods(uintptr(p0[0]), uintptr(p1))
 		return
 	}
+	gdextension.Host.ClassDB.Register.Virtual = func(p0 gdextension.StringName, p1 gdextension.StringName, p2 gdextension.MethodFlags, p3 gdextension.PropertyList, p4 gdextension.PropertyList) {
+		gd_classdb_register_virtual(uintptr(p0[0]), uintptr(p1[0]), uint32(p2), uintptr(p3), uintptr(p4))
+		return
+	}
 	gdextension.Host.ClassDB.Register.Constant = func(p0 gdextension.StringName, p1 gdextension.StringName, p2 gdextension.StringName, p3 int64, p4 bool) {
 		gd_classdb_register_constant(uintptr(p0[0]), uintptr(p1[0]), uintptr(p2[0]), int64(p3), bool(p4))
 		return
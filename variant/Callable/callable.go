@@ -28,14 +28,23 @@ type functionCall struct {
 }
 
 // queue of functions to call later.
-var queue = Array.New[functionCall]()
+var (
+	queue      = Array.New[functionCall]()
+	queueMutex sync.Mutex
+)
 
-// Cycle calls all functions in the defer queue.
+// Cycle calls all functions in the defer queue. Functions deferred while the queue is
+// being processed will be called on the next cycle. Any [Coroutine] that is ready to
+// be resumed is resumed afterwards.
 func Cycle() {
-	for _, queued := range queue.Iter() {
+	queueMutex.Lock()
+	var calls = queue.Slice()
+	queue = Array.New[functionCall]()
+	queueMutex.Unlock()
+	for _, queued := range calls {
 		queued.function.Call(queued.arguments...)
 	}
-	Array.Clear(queue)
+	cycleCoroutines(false)
 }
 
 // New returns a new [Func] from the given value, if the value is not a Go func
@@ -150,11 +159,14 @@ func (fn Function) Call(args ...variant.Any) variant.Any { //gd:Callable.call Ca
 }
 
 // Defer calls the function represented by this callable at the end of the current frame.
-// Arguments can be passed and should match the method's signature.
+// Arguments can be passed and should match the method's signature. Safe to call from any
+// goroutine.
 func Defer(fn Function, args ...variant.Any) { //gd:Callable.call_deferred
 	if fn == Nil {
 		return
 	}
+	queueMutex.Lock()
+	defer queueMutex.Unlock()
 	queue.Append(functionCall{
 		function:  fn,
 		arguments: args,
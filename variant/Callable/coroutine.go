@@ -0,0 +1,262 @@
+package Callable
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Coroutine is a function started with [Spawn] that runs cooperatively on the main thread, it
+// is suspended whenever it waits on its [Yield] and resumed by [Cycle] (or [PhysicsCycle]) once
+// the condition it is waiting for holds.
+type Coroutine struct {
+	name      string
+	yield     func(until) bool
+	next      func() (until, bool)
+	stop      func()
+	until     until
+	started   bool
+	cancelled atomic.Bool
+	done      chan struct{}
+}
+
+// Yield is passed to the function started by [Spawn], each method suspends the coroutine until
+// the respective condition holds. Yield must only be used from within the coroutine it was passed
+// to.
+type Yield struct {
+	co *Coroutine
+}
+
+// Emitter is implemented by signals, see [Yield.Signal].
+type Emitter interface {
+	// Emitted starts watching for the next emission, the done function reports whether it has
+	// happened and cancel stops watching.
+	Emitted() (done func() bool, cancel func())
+}
+
+type waitKind int
+
+const (
+	waitFrame waitKind = iota
+	waitPhysicsFrame
+	waitTime
+	waitSignal
+)
+
+// until is the condition a coroutine is waiting for.
+type until struct {
+	kind    waitKind
+	at      float64 // game time, in seconds.
+	emitted func() bool
+}
+
+// clock is the game time in seconds, as advanced by [Advance].
+var clock struct {
+	sync.Mutex
+	now float64
+}
+
+// Advance the game time that [Yield.Seconds] waits on by delta seconds, it should be called once
+// per process frame with the delta of that frame, which is scaled by Engine.time_scale and which
+// does not advance while the scene tree is paused, just like the timers that GDScript awaits.
+func Advance(delta float64) {
+	clock.Lock()
+	clock.now += delta
+	clock.Unlock()
+}
+
+// gameTime returns the current game time, in seconds.
+func gameTime() float64 {
+	clock.Lock()
+	defer clock.Unlock()
+	return clock.now
+}
+
+// errCancelled unwinds a cancelled coroutine.
+type errCancelled struct{}
+
+// coroutines protects the started and until fields of each coroutine, so that they can be listed
+// from any goroutine with [Coroutines].
+var coroutines struct {
+	sync.Mutex
+	running []*Coroutine
+}
+
+// Spawn starts fn as a [Coroutine], it will begin running on the main thread during the next
+// [Cycle]. Safe to call from any goroutine.
+//
+//	Callable.Spawn(func(y Callable.Yield) {
+//		door.Open()
+//		y.Seconds(1.5)
+//		player.Walk()
+//		y.Signal(player.Arrived)
+//		door.Close()
+//	})
+func Spawn(fn func(Yield)) *Coroutine {
+	co := &Coroutine{
+		name: runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name(),
+		done: make(chan struct{}),
+	}
+	co.next, co.stop = iter.Pull(func(yield func(until) bool) {
+		defer func() {
+			if r := recover(); r != nil {
+				if _, ok := r.(errCancelled); !ok {
+					panic(r)
+				}
+			}
+		}()
+		co.yield = yield
+		fn(Yield{co})
+	})
+	coroutines.Lock()
+	coroutines.running = append(coroutines.running, co)
+	coroutines.Unlock()
+	return co
+}
+
+// Coroutines returns a sequence of the coroutines that are currently running, intended for
+// debugging purposes.
+func Coroutines() iter.Seq[*Coroutine] {
+	coroutines.Lock()
+	running := append([]*Coroutine(nil), coroutines.running...)
+	coroutines.Unlock()
+	return func(yield func(*Coroutine) bool) {
+		for _, co := range running {
+			if !yield(co) {
+				return
+			}
+		}
+	}
+}
+
+// Cancel stops the coroutine the next time it would be resumed. Safe to call from any goroutine.
+func (co *Coroutine) Cancel() { co.cancelled.Store(true) }
+
+// Done returns a channel that is closed once the coroutine has returned or has been cancelled.
+func (co *Coroutine) Done() <-chan struct{} { return co.done }
+
+// String returns the name of the coroutine's function, along with what it is waiting for.
+func (co *Coroutine) String() string {
+	coroutines.Lock()
+	started, condition := co.started, co.until
+	coroutines.Unlock()
+	if !started {
+		return co.name + " (starting)"
+	}
+	switch condition.kind {
+	case waitPhysicsFrame:
+		return co.name + " (waiting for physics frame)"
+	case waitTime:
+		return fmt.Sprintf("%s (waiting for %.3fs)", co.name, condition.at-gameTime())
+	case waitSignal:
+		return co.name + " (waiting for signal)"
+	default:
+		return co.name + " (waiting for frame)"
+	}
+}
+
+// Frame suspends the coroutine until the next frame.
+func (y Yield) Frame() { y.co.wait(until{kind: waitFrame}) }
+
+// PhysicsFrame suspends the coroutine until the next physics frame.
+func (y Yield) PhysicsFrame() { y.co.wait(until{kind: waitPhysicsFrame}) }
+
+// Seconds suspends the coroutine until at least the given number of seconds of game time have
+// passed, see [Advance].
+func (y Yield) Seconds(seconds float64) {
+	y.co.wait(until{kind: waitTime, at: gameTime() + seconds})
+}
+
+// Signal suspends the coroutine until the given signal is emitted.
+func (y Yield) Signal(signal Emitter) {
+	emitted, cancel := signal.Emitted()
+	defer cancel()
+	y.co.wait(until{kind: waitSignal, emitted: emitted})
+}
+
+func (co *Coroutine) wait(condition until) {
+	if co.cancelled.Load() || !co.yield(condition) {
+		panic(errCancelled{})
+	}
+}
+
+// ready reports whether the coroutine can be resumed.
+func (co *Coroutine) ready(physics bool, now float64) bool {
+	if !co.started || co.cancelled.Load() {
+		return !physics
+	}
+	switch co.until.kind {
+	case waitFrame:
+		return !physics
+	case waitPhysicsFrame:
+		return physics
+	case waitTime:
+		return !physics && now >= co.until.at
+	case waitSignal:
+		return co.until.emitted()
+	default:
+		return false
+	}
+}
+
+// resume the coroutine, reporting whether it is still running.
+func (co *Coroutine) resume() (running bool) {
+	defer func() {
+		if !running {
+			close(co.done)
+		}
+	}()
+	if co.cancelled.Load() {
+		co.stop()
+		return false
+	}
+	coroutines.Lock()
+	co.started = true
+	coroutines.Unlock()
+	condition, running := co.next()
+	coroutines.Lock()
+	co.until = condition
+	coroutines.Unlock()
+	return running
+}
+
+// cycleCoroutines resumes all of the coroutines that are ready to be resumed.
+func cycleCoroutines(physics bool) {
+	coroutines.Lock()
+	running := append([]*Coroutine(nil), coroutines.running...)
+	coroutines.Unlock()
+	now := gameTime()
+	for _, co := range running {
+		if co.ready(physics, now) {
+			resumeOrRemove(co)
+		}
+	}
+}
+
+// resumeOrRemove resumes the coroutine, removing it from the running coroutines once it has
+// finished (including when it panics).
+func resumeOrRemove(co *Coroutine) (running bool) {
+	defer func() {
+		if running {
+			return
+		}
+		coroutines.Lock()
+		defer coroutines.Unlock()
+		for i, other := range coroutines.running {
+			if other == co {
+				coroutines.running = append(coroutines.running[:i], coroutines.running[i+1:]...)
+				break
+			}
+		}
+	}()
+	return co.resume()
+}
+
+// PhysicsCycle resumes any coroutines waiting for the next physics frame, it should be called
+// once per physics frame.
+func PhysicsCycle() {
+	cycleCoroutines(true)
+}
@@ -0,0 +1,107 @@
+package Callable_test
+
+import (
+	"testing"
+
+	"graphics.gd/variant/Callable"
+)
+
+func TestSpawn(t *testing.T) {
+	var steps []string
+	co := Callable.Spawn(func(y Callable.Yield) {
+		steps = append(steps, "start")
+		y.Frame()
+		steps = append(steps, "frame")
+		y.PhysicsFrame()
+		steps = append(steps, "physics")
+	})
+	if len(steps) != 0 {
+		t.Fatal("coroutine started before the next cycle")
+	}
+	Callable.Cycle()
+	Callable.PhysicsCycle()
+	if len(steps) != 1 {
+		t.Fatal("unexpected steps", steps)
+	}
+	Callable.Cycle()
+	Callable.Cycle()
+	if len(steps) != 2 {
+		t.Fatal("unexpected steps", steps)
+	}
+	Callable.PhysicsCycle()
+	select {
+	case <-co.Done():
+	default:
+		t.Fatal("coroutine did not finish", steps)
+	}
+	for range Callable.Coroutines() {
+		t.Fatal("finished coroutine is still listed")
+	}
+}
+
+func TestSpawnCancel(t *testing.T) {
+	var finished bool
+	co := Callable.Spawn(func(y Callable.Yield) {
+		y.Seconds(60)
+		finished = true
+	})
+	Callable.Cycle()
+	co.Cancel()
+	Callable.Cycle()
+	<-co.Done()
+	if finished {
+		t.Fatal("cancelled coroutine resumed")
+	}
+}
+
+func TestSpawnSeconds(t *testing.T) {
+	co := Callable.Spawn(func(y Callable.Yield) {
+		y.Seconds(1)
+	})
+	Callable.Cycle()
+	Callable.Advance(0.5)
+	Callable.Cycle()
+	select {
+	case <-co.Done():
+		t.Fatal("coroutine resumed before enough game time had passed")
+	default:
+	}
+	Callable.Advance(0.5)
+	Callable.Cycle()
+	select {
+	case <-co.Done():
+	default:
+		t.Fatal("coroutine did not resume once the game time had passed")
+	}
+}
+
+func TestCoroutinesListedConcurrently(t *testing.T) {
+	co := Callable.Spawn(func(y Callable.Yield) {
+		for range 10000 {
+			y.Frame()
+			y.Seconds(0)
+		}
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-co.Done():
+				return
+			default:
+			}
+			for co := range Callable.Coroutines() {
+				_ = co.String()
+			}
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+			Callable.Cycle()
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package Signal
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"sync/atomic"
+
+	"graphics.gd/variant"
+	"graphics.gd/variant/Array"
+	"graphics.gd/variant/Callable"
+)
+
+// mailbox is an unbounded queue of emissions, so that the consumer that fills it never blocks
+// the emitter (which is usually the main thread).
+type mailbox[T any] struct {
+	mutex  sync.Mutex
+	queue  []T
+	notify chan struct{}
+}
+
+func newMailbox[T any]() *mailbox[T] {
+	return &mailbox[T]{notify: make(chan struct{}, 1)}
+}
+
+func (box *mailbox[T]) send(value T) {
+	box.mutex.Lock()
+	box.queue = append(box.queue, value)
+	box.mutex.Unlock()
+	select {
+	case box.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (box *mailbox[T]) recv(ctx context.Context) (T, error) {
+	for {
+		box.mutex.Lock()
+		if len(box.queue) > 0 {
+			value := box.queue[0]
+			box.queue = box.queue[1:]
+			box.mutex.Unlock()
+			return value, nil
+		}
+		box.mutex.Unlock()
+		select {
+		case <-box.notify:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// subscribe attaches the consumer to the signal on the next frame, the returned function detaches
+// it again (also on the next frame). Both are deferred, so that they are safe to call from any
+// goroutine.
+func subscribe(signal Any, consumer Callable.Function) (unsubscribe func()) {
+	Callable.Defer(Callable.New(func() {
+		signal.Attach(consumer)
+	}))
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			Callable.Defer(Callable.New(func() {
+				signal.Remove(consumer)
+			}))
+		})
+	}
+}
+
+// next delivers the next value received by the given consumer to the returned channel, which is
+// then closed. If the context is done first, the consumer is detached and the channel is closed
+// without a value.
+func next[T any](ctx context.Context, signal Any, consumer func(func(T)) Callable.Function) <-chan T {
+	var ch = make(chan T, 1)
+	var unsubscribe func()
+	var once sync.Once
+	unsubscribe = subscribe(signal, consumer(func(value T) {
+		once.Do(func() {
+			ch <- value
+			close(ch)
+			unsubscribe()
+		})
+	}))
+	context.AfterFunc(ctx, func() {
+		once.Do(func() {
+			close(ch)
+			unsubscribe()
+		})
+	})
+	return ch
+}
+
+// await waits for the next value received by the given consumer, or for the context to be done.
+func await[T any](ctx context.Context, signal Any, consumer func(func(T)) Callable.Function) (T, error) {
+	var box = newMailbox[T]()
+	defer subscribe(signal, consumer(box.send))()
+	return box.recv(ctx)
+}
+
+// values returns an iterator over the values received by the given consumer, until the context is
+// done or the iteration is stopped.
+func values[T any](ctx context.Context, signal Any, consumer func(func(T)) Callable.Function) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var box = newMailbox[T]()
+		defer subscribe(signal, consumer(box.send))()
+		for {
+			value, err := box.recv(ctx)
+			if err != nil || !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Next returns a channel that receives a value when the signal is next emitted. Safe to call
+// from any goroutine. If the signal is never emitted, the consumer stays attached to it (and the
+// channel stays reachable) for as long as the signal exists, use [Void.NextContext] to bound this.
+func (signal Void) Next() <-chan struct{} {
+	return signal.NextContext(context.Background())
+}
+
+// NextContext is like [Void.Next], except that the channel is closed without a value (and the
+// consumer is detached) if the context is done before the signal is emitted.
+func (signal Void) NextContext(ctx context.Context) <-chan struct{} {
+	return next(ctx, signal.Any, func(send func(struct{})) Callable.Function {
+		return Callable.New(func() { send(struct{}{}) })
+	})
+}
+
+// Await blocks until the signal is emitted, or until the context is done, in which case the
+// context's error is returned. Safe to call from any goroutine other than the main thread,
+// as emissions are delivered on the main thread.
+func (signal Void) Await(ctx context.Context) error {
+	_, err := await(ctx, signal.Any, func(send func(struct{})) Callable.Function {
+		return Callable.New(func() { send(struct{}{}) })
+	})
+	return err
+}
+
+// Values returns an iterator that yields once for each emission of the signal, until the context
+// is done. Safe to range over from any goroutine other than the main thread.
+func (signal Void) Values(ctx context.Context) iter.Seq[struct{}] {
+	return values(ctx, signal.Any, func(send func(struct{})) Callable.Function {
+		return Callable.New(func() { send(struct{}{}) })
+	})
+}
+
+// Next returns a channel that receives the value of the next emission of the signal. Safe to
+// call from any goroutine. If the signal is never emitted, the consumer stays attached to it (and
+// the channel stays reachable) for as long as the signal exists, use [Solo.NextContext] to bound
+// this.
+func (signal Solo[A]) Next() <-chan A {
+	return signal.NextContext(context.Background())
+}
+
+// NextContext is like [Solo.Next], except that the channel is closed without a value (and the
+// consumer is detached) if the context is done before the signal is emitted.
+func (signal Solo[A]) NextContext(ctx context.Context) <-chan A {
+	return next(ctx, signal.Any, func(send func(A)) Callable.Function {
+		return Callable.New(send)
+	})
+}
+
+// Await blocks until the signal is emitted and returns the emitted value, or until the context
+// is done, in which case the context's error is returned. Safe to call from any goroutine other
+// than the main thread, as emissions are delivered on the main thread.
+//
+//	answer, err := dialogue.Answered.Await(ctx)
+func (signal Solo[A]) Await(ctx context.Context) (A, error) {
+	return await(ctx, signal.Any, func(send func(A)) Callable.Function {
+		return Callable.New(send)
+	})
+}
+
+// Values returns an iterator over each value emitted by the signal, until the context is done.
+// Safe to range over from any goroutine other than the main thread.
+func (signal Solo[A]) Values(ctx context.Context) iter.Seq[A] {
+	return values(ctx, signal.Any, func(send func(A)) Callable.Function {
+		return Callable.New(send)
+	})
+}
+
+// PairValues are the values of an emission of a [Pair] signal.
+type PairValues[A, B any] struct {
+	A A
+	B B
+}
+
+// Next returns a channel that receives the values of the next emission of the signal. Safe to
+// call from any goroutine. If the signal is never emitted, the consumer stays attached to it (and
+// the channel stays reachable) for as long as the signal exists, use [Pair.NextContext] to bound
+// this.
+func (signal Pair[A, B]) Next() <-chan PairValues[A, B] {
+	return signal.NextContext(context.Background())
+}
+
+// NextContext is like [Pair.Next], except that the channel is closed without a value (and the
+// consumer is detached) if the context is done before the signal is emitted.
+func (signal Pair[A, B]) NextContext(ctx context.Context) <-chan PairValues[A, B] {
+	return next(ctx, signal.Any, func(send func(PairValues[A, B])) Callable.Function {
+		return Callable.New(func(a A, b B) { send(PairValues[A, B]{a, b}) })
+	})
+}
+
+// Await blocks until the signal is emitted and returns the emitted values, or until the context
+// is done, in which case the context's error is returned. Safe to call from any goroutine other
+// than the main thread, as emissions are delivered on the main thread.
+func (signal Pair[A, B]) Await(ctx context.Context) (A, B, error) {
+	v, err := await(ctx, signal.Any, func(send func(PairValues[A, B])) Callable.Function {
+		return Callable.New(func(a A, b B) { send(PairValues[A, B]{a, b}) })
+	})
+	return v.A, v.B, err
+}
+
+// Values returns an iterator over each pair of values emitted by the signal, until the context is
+// done. Safe to range over from any goroutine other than the main thread.
+func (signal Pair[A, B]) Values(ctx context.Context) iter.Seq2[A, B] {
+	seq := values(ctx, signal.Any, func(send func(PairValues[A, B])) Callable.Function {
+		return Callable.New(func(a A, b B) { send(PairValues[A, B]{a, b}) })
+	})
+	return func(yield func(A, B) bool) {
+		for v := range seq {
+			if !yield(v.A, v.B) {
+				return
+			}
+		}
+	}
+}
+
+// TrioValues are the values of an emission of a [Trio] signal.
+type TrioValues[A, B, C any] struct {
+	A A
+	B B
+	C C
+}
+
+func trio[A, B, C any](send func(TrioValues[A, B, C])) Callable.Function {
+	return Callable.New(func(a A, b B, c C) { send(TrioValues[A, B, C]{a, b, c}) })
+}
+
+// Next returns a channel that receives the values of the next emission of the signal. Safe to
+// call from any goroutine. If the signal is never emitted, the consumer stays attached to it (and
+// the channel stays reachable) for as long as the signal exists, use [Trio.NextContext] to bound
+// this.
+func (signal Trio[A, B, C]) Next() <-chan TrioValues[A, B, C] {
+	return signal.NextContext(context.Background())
+}
+
+// NextContext is like [Trio.Next], except that the channel is closed without a value (and the
+// consumer is detached) if the context is done before the signal is emitted.
+func (signal Trio[A, B, C]) NextContext(ctx context.Context) <-chan TrioValues[A, B, C] {
+	return next(ctx, signal.Any, trio[A, B, C])
+}
+
+// Await blocks until the signal is emitted and returns the emitted values, or until the context
+// is done, in which case the context's error is returned. Safe to call from any goroutine other
+// than the main thread, as emissions are delivered on the main thread.
+func (signal Trio[A, B, C]) Await(ctx context.Context) (A, B, C, error) {
+	v, err := await(ctx, signal.Any, trio[A, B, C])
+	return v.A, v.B, v.C, err
+}
+
+// Values returns an iterator over the values of each emission of the signal, until the context
+// is done. Safe to range over from any goroutine other than the main thread.
+func (signal Trio[A, B, C]) Values(ctx context.Context) iter.Seq[TrioValues[A, B, C]] {
+	return values(ctx, signal.Any, trio[A, B, C])
+}
+
+// emitted is a [Callable.Proxy] that records whether it has been called, regardless of the
+// arguments it was called with.
+type emitted struct {
+	called atomic.Bool
+}
+
+func (e *emitted) Name(complex128) string                                       { return "Signal.Emitted" }
+func (e *emitted) Args(complex128) (int, Array.Any)                             { return 0, Array.Nil }
+func (e *emitted) Bind(complex128, ...variant.Any) (Callable.Proxy, complex128) { return e, 0 }
+func (e *emitted) Call(complex128, ...variant.Any) variant.Any {
+	e.called.Store(true)
+	return variant.Nil
+}
+
+// Emitted attaches a consumer to the signal, such that done reports whether the signal has been
+// emitted since, cancel removes the consumer. Must be called on the main thread, this is what
+// enables a [Callable.Coroutine] to wait for a signal with [Callable.Yield.Signal].
+func (signal Any) Emitted() (done func() bool, cancel func()) {
+	var e emitted
+	var consumer = Callable.Through(&e, 0)
+	signal.Attach(consumer)
+	return e.called.Load, func() { signal.Remove(consumer) }
+}
@@ -0,0 +1,118 @@
+package Signal_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"graphics.gd/variant/Callable"
+	"graphics.gd/variant/Signal"
+)
+
+func TestAwait(t *testing.T) {
+	var signal Signal.Solo[int]
+	signal.Any.Attach(Callable.New(func(int) {}))
+
+	var done = make(chan int)
+	go func() {
+		value, err := signal.Await(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		done <- value
+	}()
+	next := signal.Next()
+	for {
+		Callable.Cycle()
+		signal.Emit(42)
+		select {
+		case value := <-done:
+			if value != 42 {
+				t.Fatal("unexpected value", value)
+			}
+			if value := <-next; value != 42 {
+				t.Fatal("unexpected next value", value)
+			}
+			return
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestAwaitCancel(t *testing.T) {
+	var signal Signal.Solo[int]
+	signal.Any.Attach(Callable.New(func(int) {}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := signal.Await(ctx); err != context.Canceled {
+		t.Fatal("expected context.Canceled, got", err)
+	}
+}
+
+func TestValues(t *testing.T) {
+	var signal Signal.Pair[int, string]
+	signal.Any.Attach(Callable.New(func(int, string) {}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var done = make(chan int)
+	go func() {
+		var sum int
+		for a, b := range signal.Values(ctx) {
+			sum += a
+			if b == "stop" {
+				break
+			}
+		}
+		done <- sum
+	}()
+	for i := 1; ; i++ {
+		Callable.Cycle()
+		if i > 3 {
+			signal.Emit(1, "stop")
+		} else {
+			signal.Emit(1, "")
+		}
+		select {
+		case sum := <-done:
+			if sum == 0 {
+				t.Fatal("no values received")
+			}
+			return
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestNextContext(t *testing.T) {
+	var signal Signal.Solo[int]
+	signal.Any.Attach(Callable.New(func(int) {}))
+	ctx, cancel := context.WithCancel(context.Background())
+	next := signal.NextContext(ctx)
+	cancel()
+	select {
+	case _, ok := <-next:
+		if ok {
+			t.Fatal("expected the channel to be closed without a value")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel was not closed when the context was cancelled")
+	}
+}
+
+func TestPairNext(t *testing.T) {
+	var signal Signal.Pair[int, string]
+	signal.Any.Attach(Callable.New(func(int, string) {}))
+	next := signal.Next()
+	for {
+		Callable.Cycle()
+		signal.Emit(1, "one")
+		select {
+		case values := <-next:
+			if values.A != 1 || values.B != "one" {
+				t.Fatal("unexpected values", values)
+			}
+			return
+		case <-time.After(time.Millisecond):
+		}
+	}
+}